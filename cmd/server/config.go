@@ -0,0 +1,189 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/StevenCyb/GoCLI/pkg/cli"
+	"github.com/StevenCyb/ServMock/pkg/har"
+	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/StevenCyb/ServMock/pkg/openapi"
+	"github.com/StevenCyb/ServMock/pkg/setup"
+)
+
+// configLoader builds behavior sets from INI configs, OpenAPI specs or HAR archives.
+type configLoader struct {
+	buildOptions []setup.Option
+	harOptions   har.Options
+}
+
+// harOptions reads the HAR replay options shared by the server and the tools.
+func harOptions(ctx *cli.Context) har.Options {
+	return har.Options{
+		Match:         har.Match(*ctx.GetOption("har_match")),
+		ReplayTimings: *ctx.GetOption("har_timings") == "true",
+	}
+}
+
+// configExtensions are the extensions of supported config files: INI configs, OpenAPI specs and HAR archives.
+var configExtensions = []string{".ini", ".yaml", ".yml", ".json", ".har"}
+
+// configFiles resolves the config path to its files in lexical order.
+// The path is either a single file, a directory whose INI files are used, or a glob pattern.
+// Files and glob matches with other than the config extensions are rejected.
+func configFiles(path string) ([]string, error) {
+	var files []string
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config directory: %w", err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".ini") {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	} else if err == nil {
+		files = []string{path}
+	} else {
+		matches, globErr := filepath.Glob(path)
+		if globErr != nil {
+			return nil, fmt.Errorf("invalid config path pattern: %w", globErr)
+		}
+		files = matches
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no config files found at %s", path)
+	}
+	for _, file := range files {
+		if !slices.Contains(configExtensions, filepath.Ext(file)) {
+			return nil, fmt.Errorf("unsupported config file %s, expected one of %s", file, strings.Join(configExtensions, ", "))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// watchedFiles resolves the config path like configFiles and adds the files included by its INI files.
+func watchedFiles(path string) ([]string, error) {
+	files, err := configFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	var watched []string
+	for _, file := range files {
+		included := []string{file}
+		if strings.HasSuffix(file, ".ini") {
+			// Broken includes are reported by the load, the files found so far are watched anyway.
+			included, _ = ini.Files(file)
+		}
+		for _, f := range included {
+			if !slices.Contains(watched, f) {
+				watched = append(watched, f)
+			}
+		}
+	}
+	return watched, nil
+}
+
+// hashFiles returns the SHA-256 hash over the names and contents of the files, skipping unreadable ones.
+func hashFiles(files []string) string {
+	h := sha256.New()
+	for _, file := range files {
+		h.Write([]byte(file))
+		h.Write([]byte{0})
+		if raw, err := os.ReadFile(file); err == nil {
+			h.Write(raw)
+		}
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// load builds the behavior set of the config path and returns it with the SHA-256 hash of all its files.
+// Several INI files are merged in lexical order, see setup.Build for the precedence.
+func (l *configLoader) load(path string) (*model.BehaviorSet, string, error) {
+	files, err := watchedFiles(path)
+	if err != nil {
+		return nil, "", err
+	}
+	hash := hashFiles(files)
+
+	// Included files are part of the sections returned by ini.ParseFile, only the config files are loaded.
+	if files, err = configFiles(path); err != nil {
+		return nil, hash, err
+	}
+	var bs *model.BehaviorSet
+	if len(files) > 1 {
+		bs, err = l.loadGroup(files)
+	} else {
+		bs, err = l.loadFile(files[0])
+	}
+	return bs, hash, err
+}
+
+// loadFile builds the behavior set of a single file depending on its type.
+func (l *configLoader) loadFile(path string) (*model.BehaviorSet, error) {
+	var bs *model.BehaviorSet
+	var err error
+	switch {
+	case strings.HasSuffix(path, ".har"):
+		archive, loadErr := har.Load(path)
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		bs, err = har.Build(archive, l.harOptions)
+	case strings.HasSuffix(path, ".ini"):
+		sections, parseErr := ini.ParseFile(path, true)
+		if parseErr != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", parseErr)
+		}
+		return l.buildSections(sections)
+	default:
+		doc, loadErr := openapi.Load(path)
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		bs, err = openapi.Build(doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to build behavior set: %w", err)
+	}
+	return bs, nil
+}
+
+// loadGroup builds one behavior set from several INI files.
+func (l *configLoader) loadGroup(files []string) (*model.BehaviorSet, error) {
+	var sections []ini.Section
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".ini") {
+			return nil, fmt.Errorf("only INI config files can be combined: %s", file)
+		}
+		fileSections, err := ini.ParseFile(file, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		sections = append(sections, fileSections...)
+	}
+	return l.buildSections(sections)
+}
+
+// buildSections resolves variable references of INI sections and builds their behavior set.
+func (l *configLoader) buildSections(sections []ini.Section) (*model.BehaviorSet, error) {
+	sections, err := ini.Interpolate(sections, os.LookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve variables: %w", err)
+	}
+	bs, err := setup.Build(sections, l.buildOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build behavior set: %w", err)
+	}
+	return bs, nil
+}
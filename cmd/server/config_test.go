@@ -0,0 +1,130 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	users := writeFile(t, dir, "mocks/users.ini", "[GET /users]\n")
+	pets := writeFile(t, dir, "mocks/pets.ini", "[GET /pets]\n")
+	writeFile(t, dir, "mocks/notes.txt", "not a config")
+	writeFile(t, dir, "mocks/shared/auth.ini", "header = Authorization: test\n")
+	spec := writeFile(t, dir, "openapi.yaml", "openapi: 3.0.0\n")
+	notes := filepath.Join(dir, "mocks/notes.txt")
+
+	for _, tc := range []struct {
+		name     string
+		path     string
+		expected []string
+		err      string
+	}{
+		{name: "File", path: users, expected: []string{users}},
+		{name: "Spec", path: spec, expected: []string{spec}},
+		{name: "Directory", path: filepath.Join(dir, "mocks"), expected: []string{pets, users}},
+		{name: "Glob", path: filepath.Join(dir, "mocks/*s.ini"), expected: []string{pets, users}},
+		{name: "Missing", path: filepath.Join(dir, "missing.ini"), err: "no config files found at " + filepath.Join(dir, "missing.ini")},
+		{name: "InvalidPattern", path: filepath.Join(dir, "[.ini"), err: "invalid config path pattern: syntax error in pattern"},
+		{
+			name: "UnsupportedFile", path: notes,
+			err: "unsupported config file " + notes + ", expected one of .ini, .yaml, .yml, .json, .har",
+		},
+		{
+			name: "UnsupportedGlobMatch", path: filepath.Join(dir, "mocks/*"),
+			err: "unsupported config file " + notes + ", expected one of .ini, .yaml, .yml, .json, .har",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := configFiles(tc.path)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, files)
+		})
+	}
+}
+
+func TestWatchedFiles(t *testing.T) {
+	dir := t.TempDir()
+	auth := writeFile(t, dir, "shared/auth.ini", "header = Authorization: test\n")
+	pets := writeFile(t, dir, "pets.ini", "[GET /pets]\n@include = shared/auth.ini\n")
+	users := writeFile(t, dir, "users.ini", "[GET /users]\n@include = shared/auth.ini\n@include = shared/missing.ini\n")
+
+	files, err := watchedFiles(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{pets, auth, users, filepath.Join(dir, "shared/missing.ini")}, files)
+}
+
+func TestConfigLoader_Load(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.ini", "status_code = 404\n[GET /users]\nbody = users\n")
+	writeFile(t, dir, "b.ini", "status_code = 418\n[GET /users]\nbody = shadowed\n[GET /pets]\n@include = shared/pets.ini\n")
+	pets := writeFile(t, dir, "shared/pets.ini", "body = pets\n")
+
+	loader := &configLoader{}
+	bs, hash, err := loader.load(dir)
+	require.NoError(t, err)
+	require.Len(t, bs.Behaviors, 3)
+	assert.Equal(t, "/users", bs.Behaviors[0].URL)
+	assert.Equal(t, "users", *bs.Behaviors[0].Body, "behaviors of earlier files win")
+	assert.Equal(t, "pets", *bs.Behaviors[2].Body)
+	assert.Equal(t, uint16(418), *bs.DefaultBehavior.StatusCode, "later default sections override earlier ones")
+
+	_, unchanged, err := loader.load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, hash, unchanged)
+
+	writeFile(t, dir, "shared/pets.ini", "body = cats\n")
+	bs, changed, err := loader.load(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, changed, "the hash covers included files")
+	assert.Equal(t, "cats", *bs.Behaviors[2].Body)
+
+	writeFile(t, dir, "shared/pets.ini", "status_code = 999\n")
+	_, broken, err := loader.load(dir)
+	require.ErrorContains(t, err, pets)
+	assert.NotEmpty(t, broken, "the hash is returned with errors")
+}
+
+func TestConfigLoader_LoadInterpolates(t *testing.T) {
+	t.Setenv("SERVMOCK_TEST_HOST", "example.com")
+	path := writeFile(t, t.TempDir(), "mocks.ini",
+		"[vars]\nversion = v1\n[GET /${version}/users]\nbody = ${SERVMOCK_TEST_HOST}/${version}\n")
+
+	bs, _, err := (&configLoader{}).load(path)
+	require.NoError(t, err)
+	require.Len(t, bs.Behaviors, 1)
+	assert.Equal(t, "/v1/users", bs.Behaviors[0].URL)
+	assert.Equal(t, "example.com/v1", *bs.Behaviors[0].Body)
+
+	path = writeFile(t, t.TempDir(), "mocks.ini", "[GET /users]\nbody = ${SERVMOCK_TEST_UNDEFINED}\n")
+	_, _, err = (&configLoader{}).load(path)
+	assert.ErrorContains(t, err, "undefined variable SERVMOCK_TEST_UNDEFINED")
+}
+
+func TestConfigLoader_LoadOnlyCombinesINI(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "mocks.ini", "[GET /users]\n")
+	writeFile(t, dir, "openapi.json", "{}")
+
+	_, _, err := (&configLoader{}).load(filepath.Join(dir, "*"))
+	assert.ErrorContains(t, err, "only INI config files can be combined")
+}
+
+func TestConfigLoader_LoadKeepsCaptureReferences(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "mocks.ini",
+		"[vars]\nprefix = /v1\n[WS ${prefix}/socket]\non = ^subscribe:(?P<topic>\\w+)$\non.send = {\"subscribed\": \"${topic}\"}\n")
+
+	bs, _, err := (&configLoader{}).load(path)
+	require.NoError(t, err)
+	require.Len(t, bs.Behaviors, 1)
+	assert.Equal(t, "/v1/socket", bs.Behaviors[0].URL)
+	require.Len(t, bs.Behaviors[0].WebSocket.Replies, 1)
+	assert.Equal(t, []string{`{"subscribed": "${topic}"}`}, bs.Behaviors[0].WebSocket.Replies[0].Frames)
+}
@@ -9,21 +9,28 @@ import (
 	"os"
 	"os/signal"
 	"regexp"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/StevenCyb/GoCLI/pkg/cli"
-	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/descriptor"
 	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/StevenCyb/ServMock/pkg/openapi"
 	"github.com/StevenCyb/ServMock/pkg/server"
 	"github.com/StevenCyb/ServMock/pkg/setup"
 	"github.com/StevenCyb/ServMock/pkg/watcher"
 )
 
+const version = "0.1.0"
 const checkFileChangeInterval = 1000
 const shutdownTimeout = 15 * time.Second
 
 func main() {
+	if isToolCommand(os.Args) {
+		os.Exit(runTools(os.Args, os.Stdout, os.Stderr))
+	}
+
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
 	}))
@@ -36,26 +43,88 @@ func main() {
   \___ \ / _ \ '__\ \ / / |\/| |/ _ \ / __| |/ /
   ____) |  __/ |   \ V /| |  | | (_) | (__|   <
  |_____/ \___|_|    \_/ |_|  |_|\___/ \___|_|\_\`),
-		cli.Description("A REST service mocking tool."),
-		cli.Version("0.1.0"),
+		cli.Description("A REST service mocking tool. Run `validate <path>` or `lint <path>` to check a config and `fmt <path>` to format it."),
+		cli.Version(version),
 		cli.Argument(
 			"path",
-			cli.Validate(regexp.MustCompile(`^.+\.ini$`)),
-			cli.Description("Path to behavior config file."),
+			cli.Description("Path to behavior config file, OpenAPI spec (.yaml, .yml, .json), HAR archive (.har), "+
+				"or a directory or glob pattern of config files (.ini) merged in lexical order."),
 			cli.Option(
 				"listen",
 				cli.Description("Port to listen on for incoming requests."),
 				cli.Short('l'),
 				cli.Default(":3000"),
 			),
+			cli.Option(
+				"seed",
+				cli.Description("Seed for reproducible probabilistic responses."),
+				cli.Short('s'),
+				cli.Validate(regexp.MustCompile(`^\d+$`)),
+			),
+			cli.Option(
+				"descriptor_set",
+				cli.Description("Path to protobuf descriptor set used for gRPC behaviors."),
+				cli.Short('d'),
+			),
+			cli.Option(
+				"openapi",
+				cli.Description("Path to OpenAPI spec used to validate incoming requests."),
+				cli.Short('o'),
+				cli.Validate(regexp.MustCompile(`^.+\.(ya?ml|json)$`)),
+			),
+			cli.Option(
+				"validation",
+				cli.Description("How to handle requests violating the OpenAPI spec: reject or log."),
+				cli.Short('v'),
+				cli.Default("reject"),
+				cli.Validate(regexp.MustCompile(`^(reject|log)$`)),
+			),
+			cli.Option(
+				"validation_status",
+				cli.Description("Status code of rejected requests, a 4xx or 5xx code."),
+				cli.Default("400"),
+				cli.Validate(regexp.MustCompile(`^[45]\d\d$`)),
+			),
+			cli.Option(
+				"conformance",
+				cli.Description("How to handle behaviors whose responses violate the OpenAPI spec or their body_schema: error or warn."),
+				cli.Short('c'),
+				cli.Default("warn"),
+				cli.Validate(regexp.MustCompile(`^(error|warn)$`)),
+			),
+			cli.Option(
+				"har_match",
+				cli.Description("Request parts telling recorded HAR entries apart: path, query (path and query) or body (path, query and body)."),
+				cli.Default("path"),
+				cli.Validate(regexp.MustCompile(`^(path|query|body)$`)),
+			),
+			cli.Option(
+				"har_timings",
+				cli.Description("Replay the recorded server timings of HAR entries as delays: true or false."),
+				cli.Default("false"),
+				cli.Validate(regexp.MustCompile(`^(true|false)$`)),
+			),
+			cli.Option(
+				"watch",
+				cli.Description("How to detect config file changes: event (file system events, falls back to polling) or poll."),
+				cli.Short('w'),
+				cli.Default("event"),
+				cli.Validate(regexp.MustCompile(`^(event|poll)$`)),
+			),
+			cli.Option(
+				"explain_match",
+				cli.Description("Explain which behavior served a request and why similar ones were rejected: off, header (X-ServMock-Match and X-ServMock-Near-Miss response headers) or log."),
+				cli.Default("off"),
+				cli.Validate(regexp.MustCompile(`^(off|header|log)$`)),
+			),
 			cli.Handler(
 				func(ctx *cli.Context) error {
 					path := ctx.GetArgument("path")
 					if path == nil {
 						return fmt.Errorf("invalid or missing path: %v", path)
 					}
-					if _, err := os.Stat(*path); err != nil {
-						return fmt.Errorf("invalid or missing path: %s", *path)
+					if _, err := configFiles(*path); err != nil {
+						return fmt.Errorf("invalid or missing path: %w", err)
 					}
 					listen := ctx.GetOption("listen")
 					if listen == nil || !regexp.MustCompile(`^(localhost|127\.0\.0\.1)?:\d+$`).MatchString(*listen) {
@@ -65,31 +134,70 @@ func main() {
 					logger.Info("Service mock listen", "listen", *listen, "path", *path)
 
 					s := server.New(*listen, &model.BehaviorSet{})
-
-					configErr := make(chan error, 1)
-					watcherErr := make(chan error, 1)
-					w := watcher.NewWatcher(*path, checkFileChangeInterval)
-					w.RegisterListener(func(path string) {
-						logger.Info("Configuration file changed", "path", path)
-						file, err := os.Open(path)
+					if seed := ctx.GetOption("seed"); seed != nil {
+						seedValue, err := strconv.ParseUint(*seed, 10, 64)
 						if err != nil {
-							watcherErr <- fmt.Errorf("failed to open config file: %w", err)
-							return
+							return fmt.Errorf("invalid seed: %s", *seed)
 						}
-						defer file.Close()
-						sections, err := ini.Parse(file, true)
+						s.SetSeed(seedValue)
+					}
+					if descriptorSet := ctx.GetOption("descriptor_set"); descriptorSet != nil {
+						files, err := descriptor.Load(*descriptorSet)
 						if err != nil {
-							watcherErr <- fmt.Errorf("failed to parse config file: %w", err)
-							return
+							return err
 						}
+						s.SetDescriptors(files)
+					}
+					s.SetExplainMode(server.ExplainMode(*ctx.GetOption("explain_match")))
+					withWarnings := setup.WithWarningHandler
+					if *ctx.GetOption("conformance") == "warn" {
+						withWarnings = setup.WithWarnings
+					}
+					buildOptions := []setup.Option{withWarnings(func(err error) {
+						logger.Warn("Configuration warning", "warning", err)
+					})}
+					if spec := ctx.GetOption("openapi"); spec != nil {
+						doc, err := openapi.Load(*spec)
+						if err != nil {
+							return err
+						}
+						buildOptions = append(buildOptions, setup.WithSpec(doc))
+						validator, err := openapi.NewValidator(doc)
+						if err != nil {
+							return err
+						}
+						statusCode, _ := strconv.Atoi(*ctx.GetOption("validation_status"))
+						s.SetValidation(&server.Validation{
+							Validator:  validator,
+							LogOnly:    *ctx.GetOption("validation") == "log",
+							StatusCode: statusCode,
+						})
+					}
 
-						bs, err := setup.Build(sections)
+					loader := &configLoader{buildOptions: buildOptions, harOptions: harOptions(ctx)}
+
+					// Only the first load is fatal, later failures keep serving the last good configuration.
+					configErr := make(chan error, 1)
+					firstLoad := true
+					w := watcher.NewWatcher(*path, checkFileChangeInterval)
+					w.SetPolling(*ctx.GetOption("watch") == "poll")
+					w.SetFiles(watchedFiles)
+					w.RegisterListener(func(path string) {
+						logger.Info("Configuration file changed", "path", path)
+						bs, hash, err := loader.load(path)
+						s.ReportReload(hash, err)
 						if err != nil {
-							configErr <- fmt.Errorf("failed to build behavior set: %w", err)
+							if firstLoad {
+								configErr <- err
+								return
+							}
+							logger.Error("Failed to reload configuration, keeping the previous one", "error", err)
 							return
 						}
 
+						firstLoad = false
 						s.SetBehaviorSet(bs)
+						logger.Info("Configuration loaded", "hash", hash)
 					})
 					w.Start()
 					defer w.Stop()
@@ -101,8 +209,6 @@ func main() {
 					select {
 					case err := <-serverError:
 						log.Printf("Server error: %v", err)
-					case err := <-watcherErr:
-						log.Printf("Watcher error: %v", err)
 					case err := <-configErr:
 						log.Printf("Configuration error: %v", err)
 					case sig := <-stop:
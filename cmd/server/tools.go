@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/StevenCyb/GoCLI/pkg/cli"
+	"github.com/StevenCyb/ServMock/pkg/diagnostic"
+	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/openapi"
+	"github.com/StevenCyb/ServMock/pkg/setup"
+)
+
+// toolCommands are the first arguments that run a tool instead of the server.
+// GoCLI does not allow commands next to the path argument of the server, so they live in their own CLI.
+var toolCommands = []string{"validate", "lint", "fmt"}
+
+// errCheckFailed indicates a config check that found problems, which were already reported.
+var errCheckFailed = errors.New("check failed")
+
+func isToolCommand(args []string) bool {
+	return len(args) > 1 && slices.Contains(toolCommands, args[1])
+}
+
+// runTools runs a tool command writing to stdout and stderr and returns the exit code.
+func runTools(args []string, stdout, stderr io.Writer) int {
+	// GoCLI options hold no state, so the commands share them instead of repeating their definitions.
+	formatOption := cli.Option(
+		"format",
+		cli.Description("Output format: text, json or sarif."),
+		cli.Short('f'),
+		cli.Default("text"),
+		cli.Validate(regexp.MustCompile(`^(text|json|sarif)$`)),
+	)
+	openAPIOption := cli.Option(
+		"openapi",
+		cli.Description("Path to OpenAPI spec the responses must conform to."),
+		cli.Short('o'),
+		cli.Validate(regexp.MustCompile(`^.+\.(ya?ml|json)$`)),
+	)
+	conformanceOption := cli.Option(
+		"conformance",
+		cli.Description("How to report responses violating the OpenAPI spec or their body_schema: error or warn."),
+		cli.Short('c'),
+		cli.Default("warn"),
+		cli.Validate(regexp.MustCompile(`^(error|warn)$`)),
+	)
+	harMatchOption := cli.Option(
+		"har_match",
+		cli.Description("Request parts telling recorded HAR entries apart: path, query (path and query) or body (path, query and body)."),
+		cli.Default("path"),
+		cli.Validate(regexp.MustCompile(`^(path|query|body)$`)),
+	)
+	harTimingsOption := cli.Option(
+		"har_timings",
+		cli.Description("Replay the recorded server timings of HAR entries as delays: true or false."),
+		cli.Default("false"),
+		cli.Validate(regexp.MustCompile(`^(true|false)$`)),
+	)
+
+	c := cli.New(
+		cli.Stream(stdout, stderr),
+		cli.Name("ServMock"),
+		cli.Description("Tools for ServMock configs."),
+		cli.Version(version),
+		cli.Command(
+			"validate",
+			cli.Description("Check a config for errors without starting the server."),
+			cli.Example("servmock validate mocks/ --format sarif"),
+			cli.Argument(
+				"path",
+				cli.Description("Path to the config, like for the server."),
+				formatOption,
+				openAPIOption,
+				conformanceOption,
+				harMatchOption,
+				harTimingsOption,
+				cli.Handler(func(ctx *cli.Context) error {
+					return check(ctx, stdout, false)
+				}),
+			),
+		),
+		cli.Command(
+			"lint",
+			cli.Description("Check a config for errors and warnings without starting the server."),
+			cli.Example("servmock lint 'mocks/*.ini' --strict true"),
+			cli.Argument(
+				"path",
+				cli.Description("Path to the config, like for the server."),
+				formatOption,
+				openAPIOption,
+				conformanceOption,
+				harMatchOption,
+				harTimingsOption,
+				cli.Option(
+					"strict",
+					cli.Description("Also fail on warnings: true or false."),
+					cli.Default("false"),
+					cli.Validate(regexp.MustCompile(`^(true|false)$`)),
+				),
+				cli.Handler(func(ctx *cli.Context) error {
+					return check(ctx, stdout, true)
+				}),
+			),
+		),
+		cli.Command(
+			"fmt",
+			cli.Description("Rewrite INI configs in canonical layout, or convert an OpenAPI spec or HAR archive to INI."),
+			cli.Example("servmock fmt mocks/ --check true"),
+			cli.Argument(
+				"path",
+				cli.Description("Path to the config, like for the server."),
+				cli.Option(
+					"check",
+					cli.Description("Only list the files that are not formatted and fail if there are any: true or false."),
+					cli.Default("false"),
+					cli.Validate(regexp.MustCompile(`^(true|false)$`)),
+				),
+				harMatchOption,
+				harTimingsOption,
+				cli.Handler(func(ctx *cli.Context) error {
+					return format(ctx, stdout)
+				}),
+			),
+		),
+	)
+
+	if _, err := c.RunWith(args); err != nil {
+		if errors.Is(err, errCheckFailed) {
+			return 1
+		}
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	return 0
+}
+
+// check loads the config like the server does and writes the problems found.
+// Warnings are only reported when linting.
+func check(ctx *cli.Context, w io.Writer, lint bool) error {
+	path := *ctx.GetArgument("path")
+	var warnings []error
+	withWarnings := setup.WithWarningHandler
+	if *ctx.GetOption("conformance") == "warn" {
+		withWarnings = setup.WithWarnings
+	}
+	buildOptions := []setup.Option{withWarnings(func(err error) {
+		warnings = append(warnings, err)
+	})}
+
+	var diagnostics []diagnostic.Diagnostic
+	if spec := ctx.GetOption("openapi"); spec != nil {
+		doc, err := openapi.Load(*spec)
+		if err != nil {
+			return err
+		}
+		buildOptions = append(buildOptions, setup.WithSpec(doc))
+	}
+	loader := &configLoader{buildOptions: buildOptions, harOptions: harOptions(ctx)}
+	_, _, err := loader.load(path)
+	diagnostics = append(diagnostics, diagnostic.FromError(diagnostic.SeverityError, err)...)
+	if lint {
+		for _, warning := range warnings {
+			diagnostics = append(diagnostics, diagnostic.FromError(diagnostic.SeverityWarning, warning)...)
+		}
+	}
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i], diagnostics[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	format := diagnostic.Format(*ctx.GetOption("format"))
+	if err := diagnostic.Write(w, format, diagnostics, "ServMock", version); err != nil {
+		return err
+	}
+	failed := diagnostic.Count(diagnostics, diagnostic.SeverityError) > 0
+	if lint && *ctx.GetOption("strict") == "true" && diagnostic.Count(diagnostics, diagnostic.SeverityWarning) > 0 {
+		failed = true
+	}
+	if failed {
+		return errCheckFailed
+	}
+	return nil
+}
+
+// format rewrites the INI files of the config path in canonical layout and lists the files it changed.
+// With check, files are only listed. A single OpenAPI spec or HAR archive is converted and written to w.
+func format(ctx *cli.Context, w io.Writer) error {
+	files, err := configFiles(*ctx.GetArgument("path"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 1 && !strings.HasSuffix(files[0], ".ini") {
+		loader := &configLoader{harOptions: harOptions(ctx)}
+		bs, err := loader.loadFile(files[0])
+		if err != nil {
+			return err
+		}
+		return ini.Write(w, setup.Sections(bs))
+	}
+
+	check := *ctx.GetOption("check") == "true"
+	unformatted := false
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".ini") {
+			return fmt.Errorf("only INI config files can be formatted together: %s", file)
+		}
+		changed, err := formatFile(file, !check)
+		if err != nil {
+			return err
+		}
+		if changed {
+			unformatted = true
+			fmt.Fprintln(w, file)
+		}
+	}
+	if check && unformatted {
+		return errCheckFailed
+	}
+	return nil
+}
+
+// formatFile formats an INI file without resolving its includes or variables
+// and reports whether the formatted content differs. The file is only rewritten if write is set.
+func formatFile(path string, write bool) (bool, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	sections, err := ini.ParseRaw(bytes.NewReader(raw))
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	var formatted bytes.Buffer
+	if err := ini.Write(&formatted, setup.Normalize(sections)); err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	if bytes.Equal(raw, formatted.Bytes()) {
+		return false, nil
+	}
+	if !write {
+		return true, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, formatted.Bytes(), info.Mode().Perm())
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestIsToolCommand(t *testing.T) {
+	assert.True(t, isToolCommand([]string{"servmock", "validate", "mocks.ini"}))
+	assert.True(t, isToolCommand([]string{"servmock", "fmt"}))
+	assert.False(t, isToolCommand([]string{"servmock", "mocks.ini"}))
+	assert.False(t, isToolCommand([]string{"servmock"}))
+}
+
+func TestRunTools_Check(t *testing.T) {
+	dir := t.TempDir()
+	valid := writeFile(t, dir, "valid.ini", "[GET /users]\nbody = []\n")
+	broken := writeFile(t, dir, "broken.ini", "[GET /users]\nstatus_code = 999\n")
+	suspicious := writeFile(t, dir, "suspicious.ini", "[GET /users]\nfoo = bar\n")
+
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		exitCode int
+		stdout   string
+		stderr   string
+	}{
+		{name: "Valid", args: []string{"validate", valid}, exitCode: 0, stdout: "0 error(s), 0 warning(s)\n"},
+		{
+			name: "Error", args: []string{"validate", broken}, exitCode: 1,
+			stdout: broken + ":2:1: error: Malformed property status_code=999: " +
+				"Invalid status code, must be an integer between 100 and 599\n1 error(s), 0 warning(s)\n",
+		},
+		{name: "ValidateIgnoresWarnings", args: []string{"validate", suspicious}, exitCode: 0, stdout: "0 error(s), 0 warning(s)\n"},
+		{
+			name: "LintWarning", args: []string{"lint", suspicious}, exitCode: 0,
+			stdout: suspicious + ":2:1: warning: foo=bar: Unknown property foo is ignored\n0 error(s), 1 warning(s)\n",
+		},
+		{
+			name: "LintStrict", args: []string{"lint", suspicious, "--strict", "true"}, exitCode: 1,
+			stdout: suspicious + ":2:1: warning: foo=bar: Unknown property foo is ignored\n0 error(s), 1 warning(s)\n",
+		},
+		{
+			name: "MissingConfig", args: []string{"validate", filepath.Join(dir, "missing.ini")}, exitCode: 1,
+			stdout: "error: no config files found at " + filepath.Join(dir, "missing.ini") + "\n1 error(s), 0 warning(s)\n",
+		},
+		{
+			name: "InvalidOption", args: []string{"validate", valid, "--format", "xml"}, exitCode: 2,
+			stderr: "invalid value for format: xml\n",
+		},
+		{
+			name: "MissingSpec", args: []string{"validate", valid, "--openapi", filepath.Join(dir, "missing.yaml")}, exitCode: 2,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			exitCode := runTools(append([]string{"servmock"}, tc.args...), &stdout, &stderr)
+			assert.Equal(t, tc.exitCode, exitCode)
+			if tc.stdout != "" {
+				assert.Equal(t, tc.stdout, stdout.String())
+			}
+			if tc.stderr != "" {
+				assert.Equal(t, tc.stderr, stderr.String())
+			}
+			if tc.exitCode == 2 {
+				assert.NotEmpty(t, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunTools_CheckConformance(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "user.json", `{"type": "object", "required": ["id"]}`)
+	path := writeFile(t, dir, "mocks.ini", "[GET /users/1]\nbody = {}\nbody_schema = user.json\n")
+	har := writeFile(t, dir, "session.har",
+		`{"log": {"entries": [{"request": {"method": "GET", "url": "http://localhost/jobs?id=1"}, "response": {"status": 200}}]}}`)
+	mismatch := path + ":2:1: %s: body={} does not conform to the JSON Schema " + filepath.Join(dir, "user.json") +
+		": Body does not match the schema: /id: property \"id\" is missing\n"
+
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		exitCode int
+		stdout   string
+	}{
+		{name: "ValidateWarn", args: []string{"validate", path}, exitCode: 0, stdout: "0 error(s), 0 warning(s)\n"},
+		{
+			name: "LintWarn", args: []string{"lint", path}, exitCode: 0,
+			stdout: fmt.Sprintf(mismatch, "warning") + "0 error(s), 1 warning(s)\n",
+		},
+		{
+			name: "ValidateError", args: []string{"validate", path, "--conformance", "error"}, exitCode: 1,
+			stdout: fmt.Sprintf(mismatch, "error") + "1 error(s), 0 warning(s)\n",
+		},
+		{
+			name: "HAROptions", args: []string{"lint", har, "--har_match", "query", "--har_timings", "true"}, exitCode: 0,
+			stdout: "0 error(s), 0 warning(s)\n",
+		},
+		{name: "InvalidHARMatch", args: []string{"validate", har, "--har_match", "host"}, exitCode: 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, tc.exitCode, runTools(append([]string{"servmock"}, tc.args...), &stdout, &stderr))
+			if tc.stdout != "" {
+				assert.Equal(t, tc.stdout, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRunTools_CheckFormats(t *testing.T) {
+	broken := writeFile(t, t.TempDir(), "broken.ini", "[GET /users]\nstatus_code = 999\n")
+
+	var stdout bytes.Buffer
+	require.Equal(t, 1, runTools([]string{"servmock", "validate", broken, "--format", "json"}, &stdout, &bytes.Buffer{}))
+	var report struct {
+		Errors      int `json:"errors"`
+		Diagnostics []struct {
+			Rule string `json:"rule"`
+			File string `json:"file"`
+			Line int    `json:"line"`
+		} `json:"diagnostics"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	assert.Equal(t, 1, report.Errors)
+	require.Len(t, report.Diagnostics, 1)
+	assert.Equal(t, "property", report.Diagnostics[0].Rule)
+	assert.Equal(t, broken, report.Diagnostics[0].File)
+	assert.Equal(t, 2, report.Diagnostics[0].Line)
+
+	stdout.Reset()
+	require.Equal(t, 1, runTools([]string{"servmock", "validate", broken, "--format", "sarif"}, &stdout, &bytes.Buffer{}))
+	var sarif struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID string `json:"ruleId"`
+				Level  string `json:"level"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &sarif))
+	assert.Equal(t, "2.1.0", sarif.Version)
+	require.Len(t, sarif.Runs, 1)
+	require.Len(t, sarif.Runs[0].Results, 1)
+	assert.Equal(t, "property", sarif.Runs[0].Results[0].RuleID)
+	assert.Equal(t, "error", sarif.Runs[0].Results[0].Level)
+}
+
+func TestRunTools_Fmt(t *testing.T) {
+	dir := t.TempDir()
+	unformatted := "[GET /users]\nbody=[]\nstatus_code=200\n"
+	formatted := "[GET /users]\nstatus_code = 200\nbody = []\n"
+	path := writeFile(t, dir, "mocks.ini", unformatted)
+	writeFile(t, dir, "other.ini", formatted)
+
+	var stdout bytes.Buffer
+	assert.Equal(t, 1, runTools([]string{"servmock", "fmt", dir, "--check", "true"}, &stdout, &bytes.Buffer{}))
+	assert.Equal(t, path+"\n", stdout.String())
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, unformatted, string(raw), "--check must not write")
+
+	stdout.Reset()
+	assert.Equal(t, 0, runTools([]string{"servmock", "fmt", dir}, &stdout, &bytes.Buffer{}))
+	assert.Equal(t, path+"\n", stdout.String())
+	raw, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, formatted, string(raw))
+
+	stdout.Reset()
+	assert.Equal(t, 0, runTools([]string{"servmock", "fmt", dir, "--check", "true"}, &stdout, &bytes.Buffer{}))
+	assert.Empty(t, stdout.String())
+}
+
+func TestRunTools_FmtInvalidLine(t *testing.T) {
+	invalid := "[GET /users]\nbody = {\n  \"name\": \"Rex\"\n}\n"
+	path := writeFile(t, t.TempDir(), "mocks.ini", invalid)
+
+	var stderr bytes.Buffer
+	assert.Equal(t, 2, runTools([]string{"servmock", "fmt", path}, &bytes.Buffer{}, &stderr))
+	assert.Contains(t, stderr.String(), path+": line 3:3: line is neither a section header, comment nor property")
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, invalid, string(raw))
+}
@@ -0,0 +1,55 @@
+package descriptor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Load reads a binary FileDescriptorSet, as produced by
+// `protoc --include_imports --descriptor_set_out=api.pb`, and returns its files.
+func Load(path string) (*protoregistry.Files, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	if err = proto.Unmarshal(raw, set); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set: %w", err)
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve descriptor set: %w", err)
+	}
+	return files, nil
+}
+
+// FindMethod looks up a method by its gRPC path like /package.Service/Method.
+func FindMethod(files *protoregistry.Files, path string) (protoreflect.MethodDescriptor, error) {
+	service, method, found := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if !found || service == "" || method == "" {
+		return nil, fmt.Errorf("invalid gRPC path: %s", path)
+	}
+
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("unknown service %s: %w", service, err)
+	}
+	serviceDesc, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", service)
+	}
+	methodDesc := serviceDesc.Methods().ByName(protoreflect.Name(method))
+	if methodDesc == nil {
+		return nil, fmt.Errorf("unknown method %s of service %s", method, service)
+	}
+	return methodDesc, nil
+}
@@ -0,0 +1,73 @@
+package descriptor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func writeDescriptorSet(t *testing.T) string {
+	t.Helper()
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("greeter.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("HelloReply"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("message"),
+				JsonName: proto.String("message"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			}},
+		}},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Greeter"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("SayHello"),
+				InputType:  proto.String(".test.HelloReply"),
+				OutputType: proto.String(".test.HelloReply"),
+			}},
+		}},
+	}}}
+	raw, err := proto.Marshal(set)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "api.pb")
+	require.NoError(t, os.WriteFile(path, raw, 0o600))
+	return path
+}
+
+func TestLoadAndFindMethod(t *testing.T) {
+	files, err := Load(writeDescriptorSet(t))
+	require.NoError(t, err)
+
+	method, err := FindMethod(files, "/test.Greeter/SayHello")
+	require.NoError(t, err)
+	assert.Equal(t, "test.HelloReply", string(method.Output().FullName()))
+
+	_, err = FindMethod(files, "/test.Greeter/Unknown")
+	require.Error(t, err)
+	_, err = FindMethod(files, "/test.Unknown/SayHello")
+	require.Error(t, err)
+	_, err = FindMethod(files, "/test.HelloReply/SayHello")
+	require.Error(t, err)
+	_, err = FindMethod(files, "/invalid")
+	require.Error(t, err)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.pb"))
+	require.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "broken.pb")
+	require.NoError(t, os.WriteFile(path, []byte("not a descriptor set"), 0o600))
+	_, err = Load(path)
+	require.Error(t, err)
+}
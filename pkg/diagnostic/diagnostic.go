@@ -0,0 +1,125 @@
+package diagnostic
+
+import (
+	"errors"
+
+	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/setup"
+)
+
+// Severity tells whether a diagnostic blocks loading the configuration.
+type Severity string
+
+const (
+	// SeverityError blocks loading the configuration.
+	SeverityError Severity = "error"
+	// SeverityWarning does not block loading the configuration.
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem found in a configuration.
+// File, Line and Column are empty if the problem has no position, e.g. a missing config file.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	File     string   `json:"file,omitempty"`
+	Line     uint64   `json:"line,omitempty"`
+	Column   uint64   `json:"column,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// Rules reported by FromError.
+const (
+	RuleSyntax         = "syntax"
+	RuleBehaviorHeader = "behavior-header"
+	RuleProperty       = "property"
+	RuleConformance    = "openapi-conformance"
+	RuleSchema         = "schema-conformance"
+	RuleWarning        = "suspicious-behavior"
+	RuleConfiguration  = "configuration"
+)
+
+// FromError turns an error, which may join several errors, into diagnostics of the given severity.
+// Wrapping errors are only kept if the errors they wrap have no position.
+func FromError(severity Severity, err error) []Diagnostic {
+	if err == nil {
+		return nil
+	}
+	if diagnostic, known := fromKnownError(err); known {
+		diagnostic.Severity = severity
+		return []Diagnostic{diagnostic}
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var diagnostics []Diagnostic
+		for _, e := range joined.Unwrap() {
+			diagnostics = append(diagnostics, FromError(severity, e)...)
+		}
+		return diagnostics
+	}
+	if wrapped := errors.Unwrap(err); wrapped != nil {
+		if diagnostics := FromError(severity, wrapped); hasPosition(diagnostics) {
+			return diagnostics
+		}
+	}
+	return []Diagnostic{{Severity: severity, Rule: RuleConfiguration, Message: err.Error()}}
+}
+
+func fromKnownError(err error) (Diagnostic, bool) {
+	switch e := err.(type) {
+	case *ini.FileError:
+		return Diagnostic{Rule: RuleSyntax, File: e.Source, Line: e.LineIndex, Column: e.Column, Message: e.Err.Error()}, true
+	case *setup.MalformedBehaviorHeaderError:
+		return Diagnostic{
+			Rule: RuleBehaviorHeader, File: e.Source, Line: e.LineIndex, Column: e.Column,
+			Message: withDetails("Malformed behavior header ["+e.Line+"]", e.Details),
+		}, true
+	case *setup.MalformedPropertyError:
+		return Diagnostic{
+			Rule: RuleProperty, File: e.Source, Line: e.LineIndex, Column: e.Column,
+			Message: withDetails("Malformed property "+e.Line, e.Details),
+		}, true
+	case *setup.ConformanceError:
+		rule := RuleConformance
+		if e.Schema != "" {
+			rule = RuleSchema
+		}
+		return Diagnostic{
+			Rule: rule, File: e.Source, Line: e.LineIndex, Column: e.Column,
+			Message: e.Line + " does not conform to " + e.Target() + ": " + e.Details,
+		}, true
+	case *setup.Warning:
+		return Diagnostic{
+			Rule: RuleWarning, File: e.Source, Line: e.LineIndex, Column: e.Column,
+			Message: e.Line + ": " + e.Details,
+		}, true
+	}
+	return Diagnostic{}, false
+}
+
+func withDetails(message string, details *string) string {
+	if details == nil {
+		return message
+	}
+	return message + ": " + *details
+}
+
+func hasPosition(diagnostics []Diagnostic) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Line > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the number of diagnostics with the given severity.
+func Count(diagnostics []Diagnostic, severity Severity) int {
+	count := 0
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
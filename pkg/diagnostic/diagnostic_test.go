@@ -0,0 +1,134 @@
+package diagnostic
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/setup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromError(t *testing.T) {
+	err := fmt.Errorf("failed to load mocks.ini: %w", errors.Join(
+		&ini.FileError{Source: "mocks.ini", LineIndex: 2, Column: 1, Err: errors.New("Missing closing bracket")},
+		&setup.MalformedPropertyError{
+			Source: "mocks.ini", LineIndex: 5, Column: 3, Line: "status_code=999", Details: setup.Ptr("Invalid status code"),
+		},
+		&setup.MalformedBehaviorHeaderError{Source: "users.ini", LineIndex: 1, Column: 1, Line: "FETCH /users"},
+	))
+
+	assert.Equal(t, []Diagnostic{
+		{Severity: SeverityError, Rule: RuleSyntax, File: "mocks.ini", Line: 2, Column: 1, Message: "Missing closing bracket"},
+		{
+			Severity: SeverityError, Rule: RuleProperty, File: "mocks.ini", Line: 5, Column: 3,
+			Message: "Malformed property status_code=999: Invalid status code",
+		},
+		{
+			Severity: SeverityError, Rule: RuleBehaviorHeader, File: "users.ini", Line: 1, Column: 1,
+			Message: "Malformed behavior header [FETCH /users]",
+		},
+	}, FromError(SeverityError, err))
+}
+
+func TestFromError_WithoutPosition(t *testing.T) {
+	err := fmt.Errorf("failed to load: %w", errors.New("no config files found"))
+	assert.Equal(t, []Diagnostic{
+		{Severity: SeverityError, Rule: RuleConfiguration, Message: "failed to load: no config files found"},
+	}, FromError(SeverityError, err))
+	assert.Nil(t, FromError(SeverityError, nil))
+}
+
+func TestFromError_Warning(t *testing.T) {
+	warning := &setup.Warning{Source: "mocks.ini", LineIndex: 4, Column: 1, Line: "repeat=0", Details: "Never runs out"}
+	assert.Equal(t, []Diagnostic{
+		{Severity: SeverityWarning, Rule: RuleWarning, File: "mocks.ini", Line: 4, Column: 1, Message: "repeat=0: Never runs out"},
+	}, FromError(SeverityWarning, warning))
+}
+
+func TestFromError_Conformance(t *testing.T) {
+	err := errors.Join(
+		&setup.ConformanceError{Source: "mocks.ini", LineIndex: 3, Column: 1, Line: "status_code=500", Details: "Not declared"},
+		&setup.ConformanceError{
+			Source: "mocks.ini", LineIndex: 4, Column: 1, Line: "body={}", Details: "Missing id", Schema: "pet.json",
+		},
+	)
+	assert.Equal(t, []Diagnostic{
+		{
+			Severity: SeverityError, Rule: RuleConformance, File: "mocks.ini", Line: 3, Column: 1,
+			Message: "status_code=500 does not conform to the OpenAPI spec: Not declared",
+		},
+		{
+			Severity: SeverityError, Rule: RuleSchema, File: "mocks.ini", Line: 4, Column: 1,
+			Message: "body={} does not conform to the JSON Schema pet.json: Missing id",
+		},
+	}, FromError(SeverityError, err))
+}
+
+var diagnostics = []Diagnostic{
+	{Severity: SeverityError, Rule: RuleProperty, File: "mocks.ini", Line: 5, Column: 3, Message: "Malformed property"},
+	{Severity: SeverityWarning, Rule: RuleWarning, File: "mocks.ini", Line: 7, Column: 1, Message: "Shadowed"},
+	{Severity: SeverityError, Rule: RuleConfiguration, Message: "no config files found"},
+}
+
+func TestWrite_Text(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, FormatText, diagnostics, "ServMock", "0.1.0"))
+	assert.Equal(t, "mocks.ini:5:3: error: Malformed property\n"+
+		"mocks.ini:7:1: warning: Shadowed\n"+
+		"error: no config files found\n"+
+		"2 error(s), 1 warning(s)\n", out.String())
+}
+
+func TestWrite_JSON(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, FormatJSON, diagnostics, "ServMock", "0.1.0"))
+
+	var result struct {
+		Errors      int          `json:"errors"`
+		Warnings    int          `json:"warnings"`
+		Diagnostics []Diagnostic `json:"diagnostics"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, 2, result.Errors)
+	assert.Equal(t, 1, result.Warnings)
+	assert.Equal(t, diagnostics, result.Diagnostics)
+
+	out.Reset()
+	require.NoError(t, Write(&out, FormatJSON, nil, "ServMock", "0.1.0"))
+	assert.JSONEq(t, `{"errors": 0, "warnings": 0, "diagnostics": []}`, out.String())
+}
+
+func TestWrite_SARIF(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, FormatSARIF, diagnostics, "ServMock", "0.1.0"))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(out.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "ServMock", run.Tool.Driver.Name)
+	assert.Len(t, run.Tool.Driver.Rules, len(ruleDescriptions))
+	require.Len(t, run.Results, 3)
+
+	assert.Equal(t, sarifResult{
+		RuleID:  RuleProperty,
+		Level:   "error",
+		Message: sarifMessage{Text: "Malformed property"},
+		Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: "mocks.ini"},
+			Region:           &sarifRegion{StartLine: 5, StartColumn: 3},
+		}}},
+	}, run.Results[0])
+	assert.Equal(t, "warning", run.Results[1].Level)
+	assert.Empty(t, run.Results[2].Locations)
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	assert.Error(t, Write(&bytes.Buffer{}, Format("xml"), diagnostics, "ServMock", "0.1.0"))
+}
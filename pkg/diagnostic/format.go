@@ -0,0 +1,185 @@
+package diagnostic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+)
+
+// Format is an output format of diagnostics.
+type Format string
+
+const (
+	// FormatText writes one `file:line:column: severity: message` line per diagnostic.
+	FormatText Format = "text"
+	// FormatJSON writes the diagnostics with their counts as JSON object.
+	FormatJSON Format = "json"
+	// FormatSARIF writes a SARIF 2.1.0 log, e.g. for code scanning in CI.
+	FormatSARIF Format = "sarif"
+)
+
+// ruleDescriptions describes the rules in SARIF logs.
+var ruleDescriptions = map[string]string{
+	RuleSyntax:         "The INI syntax, an include or a variable reference is invalid.",
+	RuleBehaviorHeader: "The section header is not a valid behavior or template.",
+	RuleProperty:       "The property has an invalid value.",
+	RuleConformance:    "The response does not conform to the OpenAPI spec.",
+	RuleSchema:         "The response body does not conform to its JSON Schema.",
+	RuleWarning:        "The behavior is valid but likely does not do what is intended.",
+	RuleConfiguration:  "The configuration could not be loaded.",
+}
+
+// Write writes the diagnostics to w in the given format.
+// Tool and version name the program in SARIF logs.
+func Write(w io.Writer, format Format, diagnostics []Diagnostic, tool, version string) error {
+	switch format {
+	case FormatText:
+		return writeText(w, diagnostics)
+	case FormatJSON:
+		return writeJSON(w, diagnostics)
+	case FormatSARIF:
+		return writeSARIF(w, diagnostics, tool, version)
+	}
+	return fmt.Errorf("unknown diagnostic format: %s", format)
+}
+
+func writeText(w io.Writer, diagnostics []Diagnostic) error {
+	for _, diagnostic := range diagnostics {
+		position := diagnostic.File
+		if diagnostic.Line > 0 {
+			position += ":" + strconv.FormatUint(diagnostic.Line, 10)
+			if diagnostic.Column > 0 {
+				position += ":" + strconv.FormatUint(diagnostic.Column, 10)
+			}
+		}
+		if position != "" {
+			position += ": "
+		}
+		if _, err := fmt.Fprintf(w, "%s%s: %s\n", position, diagnostic.Severity, diagnostic.Message); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d error(s), %d warning(s)\n",
+		Count(diagnostics, SeverityError), Count(diagnostics, SeverityWarning))
+	return err
+}
+
+func writeJSON(w io.Writer, diagnostics []Diagnostic) error {
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Errors      int          `json:"errors"`
+		Warnings    int          `json:"warnings"`
+		Diagnostics []Diagnostic `json:"diagnostics"`
+	}{
+		Errors:      Count(diagnostics, SeverityError),
+		Warnings:    Count(diagnostics, SeverityWarning),
+		Diagnostics: diagnostics,
+	})
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   uint64 `json:"startLine"`
+	StartColumn uint64 `json:"startColumn,omitempty"`
+}
+
+func writeSARIF(w io.Writer, diagnostics []Diagnostic, tool, version string) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           tool,
+			Version:        version,
+			InformationURI: "https://github.com/StevenCyb/ServMock",
+		}},
+		Results: []sarifResult{},
+	}
+	for _, rule := range []string{
+		RuleSyntax, RuleBehaviorHeader, RuleProperty, RuleConformance, RuleSchema, RuleWarning, RuleConfiguration,
+	} {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:               rule,
+			ShortDescription: sarifMessage{Text: ruleDescriptions[rule]},
+		})
+	}
+
+	for _, diagnostic := range diagnostics {
+		result := sarifResult{
+			RuleID:  diagnostic.Rule,
+			Level:   string(diagnostic.Severity),
+			Message: sarifMessage{Text: diagnostic.Message},
+		}
+		if diagnostic.File != "" {
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(diagnostic.File)},
+			}}
+			if diagnostic.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: diagnostic.Line, StartColumn: diagnostic.Column}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
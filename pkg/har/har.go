@@ -0,0 +1,231 @@
+package har
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+// Archive is the subset of a HAR 1.2 archive needed to replay its responses.
+type Archive struct {
+	Log struct {
+		Entries []Entry `json:"entries"`
+	} `json:"log"`
+}
+
+// Entry is a recorded request with its response.
+type Entry struct {
+	Time     float64  `json:"time"`
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+	Timings  Timings  `json:"timings"`
+}
+
+// Request is a recorded request.
+type Request struct {
+	Method   string    `json:"method"`
+	URL      string    `json:"url"`
+	PostData *PostData `json:"postData"`
+}
+
+// PostData is the body of a recorded request.
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	Status  int      `json:"status"`
+	Headers []Header `json:"headers"`
+	Content Content  `json:"content"`
+}
+
+// Header is a recorded header, headers can occur multiple times.
+type Header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Content is the body of a recorded response.
+type Content struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding"`
+}
+
+// Timings are the recorded phases of an entry in milliseconds, -1 if not available.
+type Timings struct {
+	Wait float64 `json:"wait"`
+}
+
+// Match selects which parts of a request are used to tell recorded entries apart.
+type Match string
+
+const (
+	// MatchPath keys behaviors on method and path.
+	MatchPath Match = "path"
+	// MatchQuery keys behaviors on method, path and query.
+	MatchQuery Match = "query"
+	// MatchBody keys behaviors on method, path, query and request body.
+	MatchBody Match = "body"
+)
+
+// Options configures how an archive is turned into behaviors.
+type Options struct {
+	Match Match
+	// ReplayTimings delays responses by the recorded server time (wait) of their entry.
+	ReplayTimings bool
+}
+
+// skippedHeaders are recorded headers that no longer apply to the replayed response.
+//
+//nolint:gochecknoglobals
+var skippedHeaders = map[string]bool{
+	"Content-Length":    true,
+	"Content-Encoding":  true,
+	"Transfer-Encoding": true,
+	"Connection":        true,
+	"Keep-Alive":        true,
+	"Set-Cookie":        true,
+}
+
+// Load reads a HAR archive.
+func Load(path string) (*Archive, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HAR file: %w", err)
+	}
+	archive := &Archive{}
+	if err = json.Unmarshal(raw, archive); err != nil {
+		return nil, fmt.Errorf("failed to parse HAR file: %w", err)
+	}
+	return archive, nil
+}
+
+// Build constructs a BehaviorSet from the entries of an archive.
+// Entries with the same key become the steps of one behavior and are replayed in recorded order,
+// after the last one the behavior keeps responding with it. Hosts are ignored, only the path is used.
+// Aborted entries without response status are skipped.
+func Build(archive *Archive, options Options) (*model.BehaviorSet, error) {
+	bs := &model.BehaviorSet{Behaviors: []*model.Behavior{}}
+	behaviors := map[string]*model.Behavior{}
+
+	for i, entry := range archive.Log.Entries {
+		if entry.Response.Status == 0 {
+			continue
+		}
+		method, match := model.HTTPMethodFromString(entry.Request.Method)
+		if !match {
+			continue
+		}
+		requestURL, err := url.Parse(entry.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: invalid URL %s: %w", i, entry.Request.URL, err)
+		}
+		response, err := buildResponse(entry, options)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+
+		path := requestURL.Path
+		if path == "" {
+			path = "/"
+		}
+		key := string(method) + " " + path
+		var query url.Values
+		var body *string
+		if options.Match == MatchQuery || options.Match == MatchBody {
+			query = requestURL.Query()
+			key += "?" + query.Encode()
+		}
+		if options.Match == MatchBody && entry.Request.PostData != nil {
+			body = &entry.Request.PostData.Text
+			key += "\n" + *body
+		}
+
+		behavior, exists := behaviors[key]
+		if !exists {
+			behavior = &model.Behavior{
+				Method:           method,
+				URL:              path,
+				Query:            query,
+				RequestBody:      body,
+				ResponseBehavior: &model.ResponseBehavior{},
+			}
+			behaviors[key] = behavior
+			bs.Behaviors = append(bs.Behaviors, behavior)
+		}
+		behavior.Steps = append(behavior.Steps, &model.Step{ResponseBehavior: response, Count: 1})
+	}
+
+	// Behaviors recorded once do not need a sequence.
+	for _, behavior := range bs.Behaviors {
+		if len(behavior.Steps) == 1 {
+			behavior.ResponseBehavior = behavior.Steps[0].ResponseBehavior
+			behavior.Steps = nil
+		}
+	}
+	return bs, nil
+}
+
+// buildResponse creates the response behavior replaying a recorded response.
+func buildResponse(entry Entry, options Options) (*model.ResponseBehavior, error) {
+	statusCode := uint16(entry.Response.Status) //nolint:gosec
+	response := &model.ResponseBehavior{StatusCode: &statusCode}
+
+	for _, header := range entry.Response.Headers {
+		name := http.CanonicalHeaderKey(header.Name)
+		if strings.HasPrefix(name, ":") {
+			// HTTP/2 pseudo headers like :status.
+			continue
+		}
+		if name == "Set-Cookie" {
+			if cookie, err := http.ParseSetCookie(header.Value); err == nil {
+				response.Cookies = append(response.Cookies, cookie)
+			}
+		}
+		if skippedHeaders[name] {
+			continue
+		}
+		if response.Headers == nil {
+			response.Headers = make(map[string]string)
+		}
+		if existing, ok := response.Headers[name]; ok {
+			response.Headers[name] = existing + ", " + header.Value
+			continue
+		}
+		response.Headers[name] = header.Value
+	}
+
+	if entry.Response.Content.Text != "" {
+		body := entry.Response.Content.Text
+		if entry.Response.Content.Encoding == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(body)
+			if err != nil {
+				return nil, fmt.Errorf("invalid base64 response body: %w", err)
+			}
+			body = string(decoded)
+		}
+		response.Body = &body
+	}
+
+	if options.ReplayTimings {
+		wait := entry.Timings.Wait
+		if wait < 0 {
+			wait = entry.Time
+		}
+		if wait > 0 {
+			delay := time.Duration(wait * float64(time.Millisecond))
+			response.Delay = &delay
+		}
+	}
+	return response, nil
+}
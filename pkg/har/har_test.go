@@ -0,0 +1,124 @@
+package har
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const session = `{
+  "log": {
+    "version": "1.2",
+    "entries": [
+      {
+        "time": 120,
+        "request": {"method": "GET", "url": "https://api.example.com/jobs/1?verbose=true"},
+        "response": {
+          "status": 202,
+          "headers": [
+            {"name": "content-type", "value": "application/json"},
+            {"name": "content-length", "value": "21"},
+            {"name": "set-cookie", "value": "session=abc; Path=/"},
+            {"name": "vary", "value": "Accept"},
+            {"name": "vary", "value": "Origin"}
+          ],
+          "content": {"mimeType": "application/json", "text": "{\"status\":\"pending\"}"}
+        },
+        "timings": {"wait": 80, "receive": 5}
+      },
+      {
+        "time": 90,
+        "request": {"method": "GET", "url": "https://api.example.com/jobs/1"},
+        "response": {
+          "status": 200,
+          "headers": [],
+          "content": {"mimeType": "application/json", "text": "eyJzdGF0dXMiOiJkb25lIn0=", "encoding": "base64"}
+        },
+        "timings": {"wait": -1}
+      },
+      {
+        "time": 10,
+        "request": {
+          "method": "POST",
+          "url": "https://api.example.com/jobs",
+          "postData": {"mimeType": "application/json", "text": "{\"name\":\"a\"}"}
+        },
+        "response": {"status": 201, "headers": [], "content": {}},
+        "timings": {"wait": 0}
+      },
+      {
+        "time": 0,
+        "request": {"method": "GET", "url": "https://api.example.com/aborted"},
+        "response": {"status": 0, "headers": [], "content": {}},
+        "timings": {"wait": -1}
+      }
+    ]
+  }
+}`
+
+func loadSession(t *testing.T) *Archive {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "session.har")
+	require.NoError(t, os.WriteFile(path, []byte(session), 0o600))
+	archive, err := Load(path)
+	require.NoError(t, err)
+	return archive
+}
+
+func TestBuild_MatchPath(t *testing.T) {
+	bs, err := Build(loadSession(t), Options{Match: MatchPath})
+	require.NoError(t, err)
+	require.Len(t, bs.Behaviors, 2)
+
+	job := bs.Behaviors[0]
+	assert.Equal(t, model.MethodGet, job.Method)
+	assert.Equal(t, "/jobs/1", job.URL)
+	assert.Nil(t, job.Query)
+	require.Len(t, job.Steps, 2)
+	assert.False(t, job.Loop)
+
+	first := job.Steps[0]
+	assert.Equal(t, uint16(202), *first.StatusCode)
+	assert.Equal(t, `{"status":"pending"}`, *first.Body)
+	assert.Equal(t, map[string]string{"Content-Type": "application/json", "Vary": "Accept, Origin"}, first.Headers)
+	require.Len(t, first.Cookies, 1)
+	assert.Equal(t, "session", first.Cookies[0].Name)
+	assert.Nil(t, first.Delay)
+
+	assert.Equal(t, `{"status":"done"}`, *job.Steps[1].Body)
+
+	create := bs.Behaviors[1]
+	assert.Empty(t, create.Steps)
+	assert.Equal(t, uint16(201), *create.StatusCode)
+	assert.Nil(t, create.Body)
+	assert.Nil(t, create.RequestBody)
+}
+
+func TestBuild_MatchBodyWithTimings(t *testing.T) {
+	bs, err := Build(loadSession(t), Options{Match: MatchBody, ReplayTimings: true})
+	require.NoError(t, err)
+	require.Len(t, bs.Behaviors, 3)
+
+	assert.Equal(t, url.Values{"verbose": {"true"}}, bs.Behaviors[0].Query)
+	assert.Equal(t, 80*time.Millisecond, *bs.Behaviors[0].Delay)
+	assert.Equal(t, url.Values{}, bs.Behaviors[1].Query)
+	assert.Equal(t, 90*time.Millisecond, *bs.Behaviors[1].Delay)
+	assert.Equal(t, `{"name":"a"}`, *bs.Behaviors[2].RequestBody)
+	assert.Nil(t, bs.Behaviors[2].Delay)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.har"))
+	require.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "broken.har")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	_, err = Load(path)
+	require.Error(t, err)
+}
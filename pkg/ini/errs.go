@@ -3,11 +3,16 @@ package ini
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // ErrEmptySectionName indicates an error in the behavior header format.
 var ErrEmptySectionName = errors.New("empty section name")
 
+// ErrInvalidLine indicates a line that is neither a section header, comment nor property.
+var ErrInvalidLine = errors.New("line is neither a section header, comment nor property")
+
 // MalformedPropertyError indicates an error in the property format.
 type EmptyKeyError struct {
 	SectionName string
@@ -17,3 +22,82 @@ type EmptyKeyError struct {
 func (e *EmptyKeyError) Error() string {
 	return fmt.Sprintf("empty key in section [%s]", e.SectionName)
 }
+
+// FileError reports the file, line and column an error originates from.
+type FileError struct {
+	Source    string
+	LineIndex uint64
+	Column    uint64
+	Err       error
+}
+
+// Error returns a string representation of the FileError.
+func (e *FileError) Error() string {
+	position := strconv.FormatUint(e.LineIndex, 10)
+	if e.Column > 0 {
+		position += ":" + strconv.FormatUint(e.Column, 10)
+	}
+	if e.Source == "" {
+		return "line " + position + ": " + e.Err.Error()
+	}
+	return e.Source + ":" + position + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// IncludeCycleError indicates a file that directly or indirectly includes itself.
+type IncludeCycleError struct {
+	Chain []string
+}
+
+// Error returns a string representation of the IncludeCycleError.
+func (e *IncludeCycleError) Error() string {
+	return "include cycle: " + strings.Join(e.Chain, " -> ")
+}
+
+// UndefinedVariableError indicates a reference to a variable that is neither defined nor set in the environment.
+type UndefinedVariableError struct {
+	Name string
+}
+
+// Error returns a string representation of the UndefinedVariableError.
+func (e *UndefinedVariableError) Error() string {
+	return "undefined variable " + e.Name
+}
+
+// VariableCycleError indicates a variable that directly or indirectly references itself.
+type VariableCycleError struct {
+	Chain []string
+}
+
+// Error returns a string representation of the VariableCycleError.
+func (e *VariableCycleError) Error() string {
+	return "variable cycle: " + strings.Join(e.Chain, " -> ")
+}
+
+// MalformedReferenceError indicates a variable reference without name or closing brace.
+type MalformedReferenceError struct {
+	Reference string
+}
+
+// Error returns a string representation of the MalformedReferenceError.
+func (e *MalformedReferenceError) Error() string {
+	return "malformed variable reference " + e.Reference
+}
+
+// MultilineValueError indicates a section name or property that cannot be written as a single INI line.
+type MultilineValueError struct {
+	SectionName string
+	Key         string
+}
+
+// Error returns a string representation of the MultilineValueError.
+func (e *MultilineValueError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("section name [%s] spans multiple lines", e.SectionName)
+	}
+	return fmt.Sprintf("property %s in section [%s] spans multiple lines", e.Key, e.SectionName)
+}
@@ -0,0 +1,171 @@
+package ini
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// VarsSection is the name of the section defining variables for interpolation.
+const VarsSection = "vars"
+
+// verbatimKeys are the properties whose values are not interpolated, since `${...}` references
+// capture groups in them, like in the replies of WebSocket behaviors.
+//
+//nolint:gochecknoglobals
+var verbatimKeys = []string{"on.send"}
+
+// Interpolate replaces `${NAME}` and `${NAME:-default}` references in section names and property values
+// and returns the sections without the vars sections.
+// A name resolves to the variable of the vars sections if defined, else to the environment looked up
+// through lookupEnv. The default is used if the name is neither defined nor set, or empty.
+// Variables of all vars sections are visible everywhere, later definitions override earlier ones,
+// and their values can reference other variables and the environment. `$${` is a literal `${`.
+// Values of verbatimKeys are kept as they are. All unresolvable references are returned joined.
+func Interpolate(sections []Section, lookupEnv func(string) (string, bool)) ([]Section, error) {
+	in := &interpolator{
+		vars:      map[string]Property{},
+		resolved:  map[string]string{},
+		lookupEnv: lookupEnv,
+	}
+	for _, section := range sections {
+		if section.Name == VarsSection {
+			for _, property := range section.Properties {
+				in.vars[property.Key] = property
+			}
+		}
+	}
+
+	result := make([]Section, 0, len(sections))
+	var errs []error
+	for _, section := range sections {
+		if section.Name == VarsSection {
+			continue
+		}
+		name, err := in.expand(section.Name)
+		if err != nil {
+			errs = append(errs, &FileError{
+				Source: section.Source, LineIndex: section.LineIndex, Column: section.Column, Err: err,
+			})
+		}
+		section.Name = name
+		properties := make([]Property, len(section.Properties))
+		for i, property := range section.Properties {
+			if slices.Contains(verbatimKeys, property.Key) {
+				properties[i] = property
+				continue
+			}
+			if property.Value, err = in.expand(property.Value); err != nil {
+				errs = append(errs, &FileError{
+					Source: property.Source, LineIndex: property.LineIndex, Column: property.Column, Err: err,
+				})
+			}
+			properties[i] = property
+		}
+		section.Properties = properties
+		result = append(result, section)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return result, nil
+}
+
+type interpolator struct {
+	vars      map[string]Property
+	resolved  map[string]string
+	lookupEnv func(string) (string, bool)
+	// resolving holds the variables being resolved to detect reference cycles.
+	resolving []string
+}
+
+func (in *interpolator) expand(value string) (string, error) {
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+	var b strings.Builder
+	for {
+		start := strings.Index(value, "${")
+		if start == -1 {
+			b.WriteString(value)
+			return b.String(), nil
+		}
+		if start > 0 && value[start-1] == '$' {
+			b.WriteString(value[:start-1])
+			b.WriteString("${")
+			value = value[start+2:]
+			continue
+		}
+		end := closingBrace(value, start+2)
+		if end == -1 {
+			return "", &MalformedReferenceError{Reference: value[start:]}
+		}
+		b.WriteString(value[:start])
+		resolved, err := in.resolve(value[start+2 : end])
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(resolved)
+		value = value[end+1:]
+	}
+}
+
+func (in *interpolator) resolve(reference string) (string, error) {
+	name, fallback, hasDefault := strings.Cut(reference, ":-")
+	if name == "" {
+		return "", &MalformedReferenceError{Reference: "${" + reference + "}"}
+	}
+
+	value, found, err := in.lookup(name)
+	if err != nil {
+		return "", err
+	}
+	if value == "" && hasDefault {
+		return in.expand(fallback)
+	}
+	if !found {
+		return "", &UndefinedVariableError{Name: name}
+	}
+	return value, nil
+}
+
+func (in *interpolator) lookup(name string) (string, bool, error) {
+	property, isVar := in.vars[name]
+	if !isVar {
+		value, found := in.lookupEnv(name)
+		return value, found, nil
+	}
+	if value, ok := in.resolved[name]; ok {
+		return value, true, nil
+	}
+	if slices.Contains(in.resolving, name) {
+		return "", false, &VariableCycleError{Chain: append(slices.Clone(in.resolving), name)}
+	}
+	in.resolving = append(in.resolving, name)
+	defer func() { in.resolving = in.resolving[:len(in.resolving)-1] }()
+	value, err := in.expand(property.Value)
+	if err != nil {
+		return "", false, &FileError{
+			Source: property.Source, LineIndex: property.LineIndex, Column: property.Column, Err: err,
+		}
+	}
+	in.resolved[name] = value
+	return value, true, nil
+}
+
+// closingBrace returns the index of the brace closing the reference starting at from, or -1.
+func closingBrace(value string, from int) int {
+	depth := 1
+	for i := from; i < len(value); i++ {
+		switch value[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
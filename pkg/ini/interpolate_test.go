@@ -0,0 +1,120 @@
+package ini
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lookupIn(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, found := env[name]
+		return value, found
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	raw := `
+redirect = https://${HOST}/login
+
+[vars]
+scheme = ${SCHEME:-https}
+base = ${scheme}://${HOST}
+cookie_domain = .${HOST}
+
+[GET ${PREFIX:-/api}/users]
+body = {"next": "${base}/users?page=2", "template": "$${literal}"}
+cookie = session; Domain=${cookie_domain}
+header = X-Empty: ${EMPTY:-fallback}
+header = X-Nested: ${MISSING:-${HOST}}
+`
+	sections, err := Parse(strings.NewReader(raw), true)
+	require.NoError(t, err)
+
+	sections, err = Interpolate(sections, lookupIn(map[string]string{"HOST": "staging.example.com", "EMPTY": ""}))
+	require.NoError(t, err)
+	require.Len(t, sections, 2)
+	assert.Equal(t, "https://staging.example.com/login", sections[0].Properties[0].Value)
+	assert.Equal(t, "GET /api/users", sections[1].Name)
+	values := []string{}
+	for _, property := range sections[1].Properties {
+		values = append(values, property.Value)
+	}
+	assert.Equal(t, []string{
+		`{"next": "https://staging.example.com/users?page=2", "template": "${literal}"}`,
+		"session; Domain=.staging.example.com",
+		"X-Empty: fallback",
+		"X-Nested: staging.example.com",
+	}, values)
+}
+
+func TestInterpolate_VarsTakePrecedence(t *testing.T) {
+	raw := `
+[vars]
+HOST = from-vars
+[vars]
+HOST = overridden
+[GET /]
+body = ${HOST}
+`
+	sections, err := Parse(strings.NewReader(raw), true)
+	require.NoError(t, err)
+
+	sections, err = Interpolate(sections, lookupIn(map[string]string{"HOST": "from-env"}))
+	require.NoError(t, err)
+	assert.Equal(t, "overridden", sections[1].Properties[0].Value)
+}
+
+func TestInterpolate_KeepsCaptureReferences(t *testing.T) {
+	raw := `
+[WS /${PREFIX}/socket]
+on = ^subscribe:(?P<topic>\w+)$
+on.send = {"subscribed": "${topic}", "first": "${1}"}
+`
+	sections, err := Parse(strings.NewReader(raw), true)
+	require.NoError(t, err)
+
+	sections, err = Interpolate(sections, lookupIn(map[string]string{"PREFIX": "v1"}))
+	require.NoError(t, err)
+	assert.Equal(t, "WS /v1/socket", sections[1].Name)
+	assert.Equal(t, `{"subscribed": "${topic}", "first": "${1}"}`, sections[1].Properties[1].Value)
+}
+
+func TestInterpolate_Errors(t *testing.T) {
+	tests := map[string]struct {
+		raw string
+		err string
+	}{
+		"undefined": {
+			raw: "[GET /]\nbody = ${HOST}\n",
+			err: "line 2:1: undefined variable HOST",
+		},
+		"undefined in vars": {
+			raw: "[vars]\nbase = ${HOST}\n[GET /]\nbody = ${base}\n",
+			err: "line 4:1: line 2:1: undefined variable HOST",
+		},
+		"cycle": {
+			raw: "[vars]\na = ${b}\nb = ${a}\n[GET /]\nbody = ${a}\n",
+			err: "line 5:1: line 2:1: line 3:1: variable cycle: a -> b -> a",
+		},
+		"unclosed": {
+			raw: "[GET /]\nbody = ${HOST\n",
+			err: "line 2:1: malformed variable reference ${HOST",
+		},
+		"empty name": {
+			raw: "[GET /${}]\n",
+			err: "line 1:1: malformed variable reference ${}",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sections, err := Parse(strings.NewReader(test.raw), true)
+			require.NoError(t, err)
+
+			_, err = Interpolate(sections, lookupIn(nil))
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
@@ -5,6 +5,12 @@ type Property struct {
 	Key       string
 	Value     string
 	LineIndex uint64
+	// Column is the 1-based column the key starts at, 0 if unknown.
+	Column uint64
+	// Source is the file the property was read from, empty when parsed from a plain reader.
+	Source string
+	// Comments are the comment lines directly preceding the property, including their `#` or `;`.
+	Comments []string
 }
 
 // Section represents a section in the INI file.
@@ -12,4 +18,12 @@ type Section struct {
 	Name       string
 	Properties []Property
 	LineIndex  uint64
+	// Column is the 1-based column of the opening bracket, 0 if unknown.
+	Column uint64
+	// Source is the file the section was read from, empty when parsed from a plain reader.
+	Source string
+	// Comments are the comment lines directly preceding the section header, including their `#` or `;`.
+	Comments []string
+	// Trailing are the comment lines after the last property of the file, set on its last section only.
+	Trailing []string
 }
@@ -2,42 +2,158 @@ package ini
 
 import (
 	"bufio"
+	"errors"
 	"io"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"unicode/utf8"
 )
 
+// IncludeKey is the key of the directive including another INI file, e.g. `@include = common/auth.ini`.
+// Properties before the first section header of the included file are added to the section containing
+// the directive and its sections are inserted at that point. Relative paths are resolved against the
+// directory of the including file.
+const IncludeKey = "@include"
+
 // Parse reads INI data from the reader and returns sections in order of appearance.
 // If `allowDuplicated=true` allows multiple sections with the same name
 // else duplicate section headers merge into the first occurrence.
+// Includes are resolved relative to the working directory.
+// All syntax errors are collected and returned joined, each as FileError with its position.
+func Parse(r io.Reader, allowDuplicated bool) ([]Section, error) {
+	p := newParser(allowDuplicated)
+	p.parse(r, "", ".")
+	if len(p.errs) > 0 {
+		return nil, errors.Join(p.errs...)
+	}
+	return p.sections, nil
+}
+
+// ParseFile reads the INI file at path like Parse, records the path as source of every
+// section and property and resolves includes relative to the directory of the including file.
+func ParseFile(path string, allowDuplicated bool) ([]Section, error) {
+	p := newParser(allowDuplicated)
+	if err := p.parseFile(path); err != nil {
+		return nil, err
+	}
+	if len(p.errs) > 0 {
+		return nil, errors.Join(p.errs...)
+	}
+	return p.sections, nil
+}
+
+// ParseRaw reads INI data like Parse with duplicated sections, but keeps `@include` directives
+// as properties instead of resolving them, e.g. to rewrite a file without inlining its includes.
+// Lines that are neither section header, comment nor property are reported as ErrInvalidLine
+// instead of being ignored, so a rewrite does not lose them.
+func ParseRaw(r io.Reader) ([]Section, error) {
+	p := newParser(true)
+	p.raw = true
+	p.parse(r, "", ".")
+	if len(p.errs) > 0 {
+		return nil, errors.Join(p.errs...)
+	}
+	return p.sections, nil
+}
+
+// Files returns the INI file at path followed by all files it includes, directly or indirectly.
+// On error the files found so far are returned with it.
+func Files(path string) ([]string, error) {
+	p := newParser(true)
+	if err := p.parseFile(path); err != nil {
+		return p.files, err
+	}
+	return p.files, errors.Join(p.errs...)
+}
+
+type parser struct {
+	allowDuplicated bool
+	raw             bool
+	sections        []Section
+	current         int
+	// including holds the absolute paths of the files being parsed to detect include cycles.
+	including []string
+	files     []string
+	errs      []error
+}
+
+func newParser(allowDuplicated bool) *parser {
+	// Initialize with global (default) section
+	return &parser{
+		allowDuplicated: allowDuplicated,
+		sections:        []Section{{Name: "default", LineIndex: 0, Properties: nil}},
+	}
+}
+
+func (p *parser) parseFile(path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(p.including, absPath) {
+		return &IncludeCycleError{Chain: append(slices.Clone(p.including), absPath)}
+	}
+	if !slices.Contains(p.files, path) {
+		p.files = append(p.files, path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if len(p.sections) == 1 && p.sections[0].Source == "" {
+		p.sections[0].Source = path
+	}
+	p.including = append(p.including, absPath)
+	defer func() { p.including = p.including[:len(p.including)-1] }()
+	p.parse(f, path, filepath.Dir(path))
+	return nil
+}
+
+// parse reads the sections of r, collecting errors in p.errs.
 //
 //nolint:gocognit,nestif
-func Parse(r io.Reader, allowDuplicated bool) ([]Section, error) {
+func (p *parser) parse(r io.Reader, source, directory string) {
 	scanner := bufio.NewScanner(r)
 	lineIndex := uint64(0)
-
-	// Initialize with global (default) section
-	sections := []Section{{Name: "default", LineIndex: lineIndex, Properties: nil}}
-	current := &sections[0]
+	// Sections of this file end with it, the includer continues in its own section.
+	including := p.current
+	defer func() { p.current = including }()
+	fail := func(column uint64, err error) {
+		p.errs = append(p.errs, &FileError{Source: source, LineIndex: lineIndex, Column: column, Err: err})
+	}
+	// comments holds the comment lines since the last section header or property.
+	var comments []string
 
 	for scanner.Scan() {
 		lineIndex++
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" {
 			continue
 		}
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			comments = append(comments, line)
+			continue
+		}
+		column := uint64(utf8.RuneCountInString(raw[:strings.Index(raw, line)])) + 1
 
 		// Section header
 		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
 			name := strings.TrimSpace(line[1 : len(line)-1])
 			if name == "" {
-				return nil, ErrEmptySectionName
+				fail(column, ErrEmptySectionName)
+				continue
 			}
 			// Find existing or append new
 			found := false
-			if !allowDuplicated {
-				for i := range sections {
-					if sections[i].Name == name {
-						current = &sections[i]
+			if !p.allowDuplicated {
+				for i := range p.sections {
+					if p.sections[i].Name == name {
+						p.current = i
 						found = true
 						break
 					}
@@ -45,9 +161,13 @@ func Parse(r io.Reader, allowDuplicated bool) ([]Section, error) {
 			}
 
 			if !found {
-				sections = append(sections, Section{Name: name, LineIndex: lineIndex, Properties: nil})
-				current = &sections[len(sections)-1]
+				p.sections = append(p.sections, Section{
+					Name: name, LineIndex: lineIndex, Column: column, Source: source, Properties: nil,
+				})
+				p.current = len(p.sections) - 1
 			}
+			p.sections[p.current].Comments = append(p.sections[p.current].Comments, comments...)
+			comments = nil
 
 			continue
 		}
@@ -57,15 +177,33 @@ func Parse(r io.Reader, allowDuplicated bool) ([]Section, error) {
 			key := strings.TrimSpace(line[:idx])
 			val := strings.TrimSpace(line[idx+1:])
 			if key == "" {
-				return nil, &EmptyKeyError{SectionName: current.Name}
+				fail(column, &EmptyKeyError{SectionName: p.sections[p.current].Name})
+				continue
+			}
+			if key == IncludeKey && !p.raw {
+				path := val
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(directory, path)
+				}
+				if err := p.parseFile(path); err != nil {
+					fail(column, err)
+				}
+				continue
 			}
-			current.Properties = append(current.Properties, Property{Key: key, Value: val, LineIndex: lineIndex})
+			p.sections[p.current].Properties = append(p.sections[p.current].Properties, Property{
+				Key: key, Value: val, LineIndex: lineIndex, Column: column, Source: source, Comments: comments,
+			})
+			comments = nil
+		} else if p.raw {
+			fail(column, ErrInvalidLine)
 		}
 	}
+	if len(comments) > 0 {
+		last := &p.sections[len(p.sections)-1]
+		last.Trailing = append(last.Trailing, comments...)
+	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, err
+		p.errs = append(p.errs, err)
 	}
-
-	return sections, nil
 }
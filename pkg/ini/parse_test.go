@@ -2,6 +2,8 @@ package ini
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -93,7 +95,8 @@ func TestEmptySectionNameError(t *testing.T) {
 key=val
 `
 	_, err := Parse(strings.NewReader(raw), false)
-	assert.Equal(t, ErrEmptySectionName, err)
+	require.ErrorIs(t, err, ErrEmptySectionName)
+	assert.EqualError(t, err, "line 1:1: empty section name")
 }
 
 func TestEmptyKeyError(t *testing.T) {
@@ -103,7 +106,7 @@ func TestEmptyKeyError(t *testing.T) {
 `
 	_, err := Parse(strings.NewReader(raw), false)
 	require.Error(t, err)
-	assert.EqualError(t, err, "empty key in section [sec]")
+	assert.EqualError(t, err, "line 3:2: empty key in section [sec]")
 }
 
 func TestScannerError(t *testing.T) {
@@ -170,3 +173,129 @@ b=2
 	assert.Equal(t, 4, int(sections[1].LineIndex))
 	assert.Equal(t, 7, int(sections[2].LineIndex))
 }
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestParseFileInclude(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.ini": `status_code = 404
+@include = common/auth.ini
+
+[GET /users]
+body = users
+`,
+		"common/auth.ini": `header = WWW-Authenticate: Bearer
+
+[POST /login]
+@include = token.ini
+status_code = 201
+`,
+		"common/token.ini": `body = {"token": "abc"}`,
+	})
+	main := filepath.Join(dir, "main.ini")
+	auth := filepath.Join(dir, "common", "auth.ini")
+	token := filepath.Join(dir, "common", "token.ini")
+
+	sections, err := ParseFile(main, true)
+	require.NoError(t, err)
+	require.Len(t, sections, 3)
+
+	assert.Equal(t, "default", sections[0].Name)
+	assert.Equal(t, main, sections[0].Source)
+	assert.Equal(t, []Property{
+		{Key: "status_code", Value: "404", LineIndex: 1, Column: 1, Source: main},
+		{Key: "header", Value: "WWW-Authenticate: Bearer", LineIndex: 1, Column: 1, Source: auth},
+	}, sections[0].Properties)
+
+	assert.Equal(t, "POST /login", sections[1].Name)
+	assert.Equal(t, auth, sections[1].Source)
+	assert.Equal(t, 3, int(sections[1].LineIndex))
+	assert.Equal(t, []Property{
+		{Key: "body", Value: `{"token": "abc"}`, LineIndex: 1, Column: 1, Source: token},
+		{Key: "status_code", Value: "201", LineIndex: 5, Column: 1, Source: auth},
+	}, sections[1].Properties)
+
+	assert.Equal(t, "GET /users", sections[2].Name)
+	assert.Equal(t, main, sections[2].Source)
+	assert.Equal(t, 4, int(sections[2].LineIndex))
+
+	files, err := Files(main)
+	require.NoError(t, err)
+	assert.Equal(t, []string{main, auth, token}, files)
+}
+
+func TestParseFileIncludeCycle(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.ini": "@include = b.ini\n",
+		"b.ini": "[GET /b]\n@include = a.ini\n",
+	})
+
+	_, err := ParseFile(filepath.Join(dir, "a.ini"), true)
+	require.Error(t, err)
+	var cycleErr *IncludeCycleError
+	require.ErrorAs(t, err, &cycleErr)
+	assert.Equal(t, []string{filepath.Join(dir, "a.ini"), filepath.Join(dir, "b.ini"), filepath.Join(dir, "a.ini")}, cycleErr.Chain)
+	assert.Contains(t, err.Error(), filepath.Join(dir, "b.ini")+":2:1: include cycle")
+}
+
+func TestParseFileIncludeMissing(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.ini": "\n@include = missing.ini\n",
+	})
+	main := filepath.Join(dir, "main.ini")
+
+	_, err := ParseFile(main, true)
+	require.Error(t, err)
+	var fileErr *FileError
+	require.ErrorAs(t, err, &fileErr)
+	assert.Equal(t, main, fileErr.Source)
+	assert.Equal(t, 2, int(fileErr.LineIndex))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	files, err := Files(main)
+	require.Error(t, err)
+	assert.Equal(t, []string{main, filepath.Join(dir, "missing.ini")}, files)
+}
+
+func TestParseFileCollectsErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.ini":   "@include = broken.ini\n[]\n  @include = missing.ini\n",
+		"broken.ini": "[sec]\n\n =value\n[ ]\n",
+	})
+	main := filepath.Join(dir, "main.ini")
+	broken := filepath.Join(dir, "broken.ini")
+
+	_, err := ParseFile(main, true)
+	require.Error(t, err)
+	assert.EqualError(t, err, strings.Join([]string{
+		broken + ":3:2: empty key in section [sec]",
+		broken + ":4:1: empty section name",
+		main + ":2:1: empty section name",
+		main + ":3:3: open " + filepath.Join(dir, "missing.ini") + ": no such file or directory",
+	}, "\n"))
+}
+
+func TestParseIncludeRelativeToWorkingDirectory(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"common.ini": "[GET /common]\n",
+	})
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	sections, err := Parse(strings.NewReader("@include = common.ini\n"), true)
+	require.NoError(t, err)
+	require.Len(t, sections, 2)
+	assert.Equal(t, "GET /common", sections[1].Name)
+	assert.Equal(t, "common.ini", sections[1].Source)
+}
@@ -0,0 +1,61 @@
+package ini
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Write writes sections as INI data in canonical layout: `key = value` properties, comments on their
+// own line before the section or property they belong to and one blank line between sections.
+// Properties of a leading default section are written before the first header without a header
+// of their own; an empty leading default section is left out.
+// Sections are written in order, names or values spanning several lines are returned as error.
+func Write(w io.Writer, sections []Section) error {
+	for _, section := range sections {
+		if err := checkSingleLine(section); err != nil {
+			return err
+		}
+	}
+
+	b := bufio.NewWriter(w)
+	written := false
+	for i, section := range sections {
+		header := i > 0 || section.Name != "default"
+		if !header && len(section.Properties) == 0 && len(section.Comments) == 0 && len(section.Trailing) == 0 {
+			continue
+		}
+		if written {
+			b.WriteString("\n")
+		}
+		writeComments(b, section.Comments)
+		if header {
+			b.WriteString("[" + section.Name + "]\n")
+		}
+		for _, property := range section.Properties {
+			writeComments(b, property.Comments)
+			b.WriteString(strings.TrimRight(property.Key+" = "+property.Value, " ") + "\n")
+		}
+		writeComments(b, section.Trailing)
+		written = true
+	}
+	return b.Flush()
+}
+
+func writeComments(b *bufio.Writer, comments []string) {
+	for _, comment := range comments {
+		b.WriteString(comment + "\n")
+	}
+}
+
+func checkSingleLine(section Section) error {
+	if strings.ContainsAny(section.Name, "\r\n") {
+		return &MultilineValueError{SectionName: section.Name}
+	}
+	for _, property := range section.Properties {
+		if strings.ContainsAny(property.Key+property.Value, "\r\n") {
+			return &MultilineValueError{SectionName: section.Name, Key: property.Key}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package ini
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRaw(t *testing.T) {
+	raw := `# Mock of the users API
+status_code=404
+@include = common/auth.ini
+
+  ; Users
+[GET /users]
+# JSON list
+body=[]
+# end of file
+`
+	sections, err := ParseRaw(strings.NewReader(raw))
+	require.NoError(t, err)
+	require.Len(t, sections, 2)
+	assert.Equal(t, []Property{
+		{Key: "status_code", Value: "404", LineIndex: 2, Column: 1, Comments: []string{"# Mock of the users API"}},
+		{Key: IncludeKey, Value: "common/auth.ini", LineIndex: 3, Column: 1},
+	}, sections[0].Properties)
+	assert.Equal(t, []string{"; Users"}, sections[1].Comments)
+	assert.Equal(t, []string{"# JSON list"}, sections[1].Properties[0].Comments)
+	assert.Equal(t, []string{"# end of file"}, sections[1].Trailing)
+}
+
+func TestParseRaw_InvalidLine(t *testing.T) {
+	raw := `[GET /users]
+body=[]
+  "name": "Rex"
+[GET /pets
+`
+	_, err := ParseRaw(strings.NewReader(raw))
+	require.ErrorIs(t, err, ErrInvalidLine)
+	assert.EqualError(t, err, "line 3:3: line is neither a section header, comment nor property\n"+
+		"line 4:1: line is neither a section header, comment nor property")
+
+	_, err = Parse(strings.NewReader(raw), true)
+	assert.NoError(t, err)
+}
+
+func TestWrite(t *testing.T) {
+	raw := `# Mock of the users API
+status_code=404
+
+  ; Users
+[ GET /users ]
+# JSON list
+body   =[]
+empty=
+# end of file
+`
+	sections, err := ParseRaw(strings.NewReader(raw))
+	require.NoError(t, err)
+
+	var out strings.Builder
+	require.NoError(t, Write(&out, sections))
+	assert.Equal(t, `# Mock of the users API
+status_code = 404
+
+; Users
+[GET /users]
+# JSON list
+body = []
+empty =
+# end of file
+`, out.String())
+
+	// Writing is idempotent.
+	formatted, err := ParseRaw(strings.NewReader(out.String()))
+	require.NoError(t, err)
+	var again strings.Builder
+	require.NoError(t, Write(&again, formatted))
+	assert.Equal(t, out.String(), again.String())
+}
+
+func TestWriteSkipsEmptyDefault(t *testing.T) {
+	var out strings.Builder
+	require.NoError(t, Write(&out, []Section{
+		{Name: "default"},
+		{Name: "GET /a", Properties: []Property{{Key: "body", Value: "a"}}},
+		{Name: "default", Properties: []Property{{Key: "status_code", Value: "418"}}},
+	}))
+	assert.Equal(t, "[GET /a]\nbody = a\n\n[default]\nstatus_code = 418\n", out.String())
+}
+
+func TestWriteMultilineValue(t *testing.T) {
+	err := Write(&strings.Builder{}, []Section{{Name: "GET /a", Properties: []Property{{Key: "body", Value: "a\nb"}}}})
+	var multiline *MultilineValueError
+	require.ErrorAs(t, err, &multiline)
+	assert.Equal(t, "property body in section [GET /a] spans multiple lines", err.Error())
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -12,20 +13,74 @@ type BehaviorSet struct {
 }
 
 // ResponseBehavior defines the structure of a response behavior.
+// BodySchema is the JSON Schema the body is checked against when the config is loaded.
 type ResponseBehavior struct {
-	Delay      *time.Duration
-	StatusCode *uint16
-	Body       *string
-	Headers    map[string]string
-	Cookies    []*http.Cookie
-	Redirect   *string
-	SSE        bool
+	Delay        *time.Duration
+	Latency      *Latency
+	ChunkDelay   *time.Duration
+	ChunkSize    *uint64
+	Bandwidth    *uint64
+	StatusCode   *uint16
+	Body         *string
+	BodySchema   *BodySchema
+	Headers      map[string]string
+	Cookies      []*http.Cookie
+	Redirect     *string
+	SSE          bool
+	EventStream  *EventStream
+	Stream       *Stream
+	WebSocket    *WebSocketScript
+	GRPC         *GRPCResponse
+	GraphQL      *GraphQLResponse
+	Proxy        *string
+	ProxyHeaders map[string]string
+	Faults       []*Fault
+	Variants     []*Variant
+}
+
+// BodySchema references a JSON Schema file.
+// Ref is the path as written in the config, Path is resolved against the directory of the config file.
+type BodySchema struct {
+	Ref  string
+	Path string
+}
+
+// Variant defines a weighted alternative response of a response behavior.
+type Variant struct {
+	*ResponseBehavior
+	Weight uint
+}
+
+// Step defines a response of a sequence that is served Count times in a row.
+type Step struct {
+	*ResponseBehavior
+	Count uint
 }
 
 // Behavior defines the structure of a behavior with an associated HTTP method and URL.
+// If Steps are defined, they are served in order and Hits tracks the progress.
+// After the last step the sequence either starts over (Loop) or sticks on the last step.
+// GraphQLMatch restricts GRAPHQL behaviors to specific operations.
+// If set, Query and RequestBody must equal the query and body of a request.
+// Origin tells where the behavior was defined, e.g. to explain which behavior served a request.
 type Behavior struct {
 	*ResponseBehavior
-	Method HTTPMethod
-	URL    string
-	Repeat *uint
+	Method       HTTPMethod
+	URL          string
+	Repeat       *uint
+	Steps        []*Step
+	Loop         bool
+	Hits         uint
+	GraphQLMatch *GraphQLMatch
+	Query        url.Values
+	RequestBody  *string
+	Origin       Origin
+}
+
+// Origin is the config section a behavior was built from.
+// Source and Line are empty if the behavior was not read from an INI file.
+type Origin struct {
+	Section string
+	Source  string
+	Line    uint64
 }
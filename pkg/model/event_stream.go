@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// EventStream defines a structured Server-Sent Events stream.
+// Interval is the default pause between events and KeepAlive the interval of comment pings.
+// Loop restarts the stream after the last event and Hold keeps the connection open
+// after the last event, both until the client disconnects.
+type EventStream struct {
+	Events    []*Event
+	Interval  *time.Duration
+	KeepAlive *time.Duration
+	Loop      bool
+	Hold      bool
+}
+
+// Event defines a single Server-Sent Event.
+// Data holds one entry per line and Delay overrides the interval of the stream before this event.
+type Event struct {
+	Type  string
+	ID    *string
+	Data  []string
+	Retry *uint
+	Delay *time.Duration
+}
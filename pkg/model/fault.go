@@ -0,0 +1,40 @@
+package model
+
+import "strings"
+
+// FaultMode represents the kind of failure injected into a response.
+type FaultMode string
+
+const (
+	FaultReset            FaultMode = "reset"
+	FaultEmpty            FaultMode = "empty"
+	FaultTruncate         FaultMode = "truncate"
+	FaultSlowBody         FaultMode = "slow_body"
+	FaultMalformedHeaders FaultMode = "malformed_headers"
+)
+
+// Fault defines a failure that is injected into a share of the responses.
+// Value holds the byte count for truncate and the bytes per second for slow_body.
+type Fault struct {
+	Mode        FaultMode
+	Value       int
+	Probability float64
+}
+
+// FaultModeFromString converts a string to a FaultMode.
+func FaultModeFromString(mode string) (FaultMode, bool) {
+	mode = strings.ToLower(mode)
+	switch mode {
+	case "reset":
+		return FaultReset, true
+	case "empty":
+		return FaultEmpty, true
+	case "truncate":
+		return FaultTruncate, true
+	case "slow_body":
+		return FaultSlowBody, true
+	case "malformed_headers":
+		return FaultMalformedHeaders, true
+	}
+	return FaultReset, false
+}
@@ -0,0 +1,39 @@
+package model
+
+import "strings"
+
+// GraphQLOperationType represents the type of a GraphQL operation.
+type GraphQLOperationType string
+
+const (
+	GraphQLQuery        GraphQLOperationType = "query"
+	GraphQLMutation     GraphQLOperationType = "mutation"
+	GraphQLSubscription GraphQLOperationType = "subscription"
+)
+
+// GraphQLOperationTypeFromString converts a string to a GraphQLOperationType.
+func GraphQLOperationTypeFromString(operationType string) (GraphQLOperationType, bool) {
+	switch GraphQLOperationType(strings.ToLower(operationType)) {
+	case GraphQLQuery:
+		return GraphQLQuery, true
+	case GraphQLMutation:
+		return GraphQLMutation, true
+	case GraphQLSubscription:
+		return GraphQLSubscription, true
+	}
+	return GraphQLQuery, false
+}
+
+// GraphQLMatch narrows down the operations a GRAPHQL behavior responds to.
+// Unset criteria match every operation, variables are compared by their decoded JSON value.
+type GraphQLMatch struct {
+	OperationName *string
+	OperationType *GraphQLOperationType
+	Variables     map[string]any
+}
+
+// GraphQLResponse defines the `data` and `errors` payloads of a GraphQL result as raw JSON.
+type GraphQLResponse struct {
+	Data   *string
+	Errors *string
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+)
+
+// GRPCResponse defines the status and trailers of a gRPC behavior.
+// The response messages are taken from the body or, for server-streaming, from the chunks.
+type GRPCResponse struct {
+	Code     uint32
+	Message  string
+	Trailers map[string]string
+}
+
+// grpcCodes lists the gRPC status code names indexed by their code.
+//
+//nolint:gochecknoglobals
+var grpcCodes = []string{
+	"OK", "CANCELED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED", "NOT_FOUND",
+	"ALREADY_EXISTS", "PERMISSION_DENIED", "RESOURCE_EXHAUSTED", "FAILED_PRECONDITION",
+	"ABORTED", "OUT_OF_RANGE", "UNIMPLEMENTED", "INTERNAL", "UNAVAILABLE", "DATA_LOSS",
+	"UNAUTHENTICATED",
+}
+
+// GRPCCodeFromString converts a gRPC status code name (e.g. NOT_FOUND) or number to its code.
+func GRPCCodeFromString(code string) (uint32, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "CANCELLED" {
+		code = "CANCELED"
+	}
+	for i, name := range grpcCodes {
+		if name == code {
+			return uint32(i), true //nolint:gosec
+		}
+	}
+	number, err := strconv.ParseUint(code, 10, 32)
+	if err != nil || number >= uint64(len(grpcCodes)) {
+		return 0, false
+	}
+	return uint32(number), true
+}
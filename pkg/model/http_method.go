@@ -13,6 +13,12 @@ const (
 	MethodPatch   HTTPMethod = "PATCH"
 	MethodHead    HTTPMethod = "HEAD"
 	MethodOptions HTTPMethod = "OPTIONS"
+	// MethodWS matches GET requests upgrading to a WebSocket connection.
+	MethodWS HTTPMethod = "WS"
+	// MethodGRPC matches gRPC calls, the URL is the full method name like /package.Service/Method.
+	MethodGRPC HTTPMethod = "GRPC"
+	// MethodGraphQL matches GraphQL operations sent via GET or POST, see GraphQLMatch.
+	MethodGraphQL HTTPMethod = "GRAPHQL"
 )
 
 // HTTPMethodFromString converts a string to an HttpMethod.
@@ -33,6 +39,12 @@ func HTTPMethodFromString(method string) (HTTPMethod, bool) {
 		return MethodHead, true
 	case "OPTIONS":
 		return MethodOptions, true
+	case "WS":
+		return MethodWS, true
+	case "GRPC":
+		return MethodGRPC, true
+	case "GRAPHQL":
+		return MethodGraphQL, true
 	}
 	return MethodGet, false
 }
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// LatencyDistribution represents the distribution a delay is sampled from.
+type LatencyDistribution string
+
+const (
+	LatencyUniform     LatencyDistribution = "uniform"
+	LatencyNormal      LatencyDistribution = "normal"
+	LatencyLogNormal   LatencyDistribution = "lognormal"
+	LatencyPercentiles LatencyDistribution = "percentiles"
+)
+
+// Latency defines a delay that is sampled per request.
+// Params are (min, max) for uniform and (mean, stddev) for normal and lognormal.
+// Percentiles are used by the percentiles distribution only.
+type Latency struct {
+	Distribution LatencyDistribution
+	Params       []time.Duration
+	Percentiles  []Percentile
+}
+
+// Percentile defines the delay at a given percentile (0-100) of a latency profile.
+type Percentile struct {
+	Percentile float64
+	Delay      time.Duration
+}
+
+// LatencyDistributionFromString converts a string to a LatencyDistribution.
+func LatencyDistributionFromString(distribution string) (LatencyDistribution, bool) {
+	distribution = strings.ToLower(distribution)
+	switch distribution {
+	case "uniform":
+		return LatencyUniform, true
+	case "normal":
+		return LatencyNormal, true
+	case "lognormal":
+		return LatencyLogNormal, true
+	}
+	return LatencyUniform, false
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// StreamMode represents the framing of a streamed response.
+type StreamMode string
+
+const (
+	StreamChunked   StreamMode = "chunked"
+	StreamNDJSON    StreamMode = "ndjson"
+	StreamMultipart StreamMode = "multipart"
+	StreamSSE       StreamMode = "sse"
+)
+
+// Stream defines a response that is written as a sequence of individually flushed chunks.
+// Boundary is used by the multipart mode only.
+type Stream struct {
+	Mode     StreamMode
+	Chunks   []*Chunk
+	Boundary string
+}
+
+// Chunk defines a single part of a streamed response.
+// Delay overrides the chunk delay before this chunk and ContentType is used by the multipart mode only.
+type Chunk struct {
+	Data        string
+	Delay       *time.Duration
+	ContentType string
+}
+
+// StreamModeFromString converts a string to a StreamMode.
+func StreamModeFromString(mode string) (StreamMode, bool) {
+	mode = strings.ToLower(mode)
+	switch mode {
+	case "chunked":
+		return StreamChunked, true
+	case "ndjson":
+		return StreamNDJSON, true
+	case "multipart":
+		return StreamMultipart, true
+	case "sse":
+		return StreamSSE, true
+	}
+	return StreamChunked, false
+}
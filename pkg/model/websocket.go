@@ -0,0 +1,31 @@
+package model
+
+import (
+	"regexp"
+	"time"
+)
+
+// WebSocketScript defines the conversation of a WebSocket behavior.
+// OnConnect frames are sent right after the upgrade, Replies answer matching client messages,
+// Ping sets the interval of ping frames and Close ends the conversation after CloseAfter.
+type WebSocketScript struct {
+	OnConnect  []string
+	Replies    []*WebSocketReply
+	Ping       *time.Duration
+	Close      *WebSocketClose
+	CloseAfter *time.Duration
+}
+
+// WebSocketReply defines the frames sent in reply to client messages matching the pattern.
+// Frames may reference capture groups of the pattern like `${1}` or `${name}`.
+type WebSocketReply struct {
+	Pattern *regexp.Regexp
+	Frames  []string
+	Close   *WebSocketClose
+}
+
+// WebSocketClose defines the close frame sent by the server.
+type WebSocketClose struct {
+	Code   int
+	Reason string
+}
@@ -0,0 +1,225 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Mismatch is a difference between a response behavior and the responses declared in an OpenAPI spec.
+type Mismatch struct {
+	// Property is the property causing the mismatch (status_code, body or header),
+	// empty if the mismatch concerns the behavior as a whole.
+	Property string
+	// Header is the name of the header for header mismatches.
+	Header  string
+	Message string
+}
+
+// CheckResponse compares a response behavior of the operation at method and url with the declared responses.
+// The status code must be declared, headers must match their schemas and required headers must be present,
+// and a JSON body must match the schema of its content type.
+// Proxied, redirected, streamed and protocol specific (WebSocket, gRPC, GraphQL) responses are not checked.
+//
+//nolint:cyclop
+func CheckResponse(doc *openapi3.T, method model.HTTPMethod, url string, response *model.ResponseBehavior) []Mismatch {
+	if method == model.MethodWS || method == model.MethodGRPC || method == model.MethodGraphQL ||
+		response.Proxy != nil || response.Redirect != nil || response.SSE || response.Stream != nil ||
+		response.WebSocket != nil || response.GRPC != nil || response.GraphQL != nil {
+		return nil
+	}
+
+	path, operation := findOperation(doc, method, url)
+	if operation == nil {
+		return []Mismatch{{Message: fmt.Sprintf("No operation %s %s declared", method, url)}}
+	}
+
+	statusCode := http.StatusOK
+	if response.StatusCode != nil {
+		statusCode = int(*response.StatusCode)
+	}
+	declared := declaredResponse(operation, statusCode)
+	if declared == nil {
+		return []Mismatch{{
+			Property: "status_code",
+			Message:  fmt.Sprintf("Status code %d is not declared for %s %s", statusCode, method, path),
+		}}
+	}
+
+	mismatches := checkHeaders(declared, response)
+	return append(mismatches, checkBody(declared, response)...)
+}
+
+// findOperation returns the path and operation of the spec that a behavior URL refers to.
+// Template segments of the spec match any segment, so /users/42 and /users/{userId} both refer to /users/{id}.
+func findOperation(doc *openapi3.T, method model.HTTPMethod, url string) (string, *openapi3.Operation) {
+	url = strings.TrimPrefix(url, basePath(doc))
+	segments := strings.Split(url, "/")
+	for _, path := range doc.Paths.InMatchingOrder() {
+		templateSegments := strings.Split(path, "/")
+		if len(templateSegments) != len(segments) {
+			continue
+		}
+		matches := true
+		for i, segment := range templateSegments {
+			if segment != segments[i] && !strings.HasPrefix(segment, "{") {
+				matches = false
+				break
+			}
+		}
+		if !matches {
+			continue
+		}
+		if operation := doc.Paths.Value(path).GetOperation(string(method)); operation != nil {
+			return path, operation
+		}
+	}
+	return url, nil
+}
+
+// declaredResponse returns the response declared for the status code, its range (e.g. 4XX) or the default response.
+func declaredResponse(operation *openapi3.Operation, statusCode int) *openapi3.Response {
+	if operation.Responses == nil {
+		return nil
+	}
+	ref := operation.Responses.Status(statusCode)
+	if ref == nil {
+		ref = operation.Responses.Default()
+	}
+	if ref == nil {
+		return nil
+	}
+	return ref.Value
+}
+
+func checkHeaders(declared *openapi3.Response, response *model.ResponseBehavior) []Mismatch {
+	names := make([]string, 0, len(declared.Headers))
+	for name := range declared.Headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var mismatches []Mismatch
+	for _, name := range names {
+		ref := declared.Headers[name]
+		if ref.Value == nil {
+			continue
+		}
+		key, value, found := findHeader(response.Headers, name)
+		if !found {
+			if ref.Value.Required {
+				mismatches = append(mismatches, Mismatch{Message: "Required header " + name + " is missing"})
+			}
+			continue
+		}
+		if ref.Value.Schema == nil || ref.Value.Schema.Value == nil {
+			continue
+		}
+		if err := ref.Value.Schema.Value.VisitJSON(headerValue(ref.Value.Schema.Value, value)); err != nil {
+			mismatches = append(mismatches, Mismatch{
+				Property: "header",
+				Header:   key,
+				Message:  "Header " + key + " does not match its schema: " + schemaErrors(err),
+			})
+		}
+	}
+	return mismatches
+}
+
+//nolint:cyclop
+func checkBody(declared *openapi3.Response, response *model.ResponseBehavior) []Mismatch {
+	if response.Body == nil {
+		return nil
+	}
+	if len(declared.Content) == 0 {
+		if *response.Body == "" {
+			return nil
+		}
+		return []Mismatch{{Property: "body", Message: "Response declares no body"}}
+	}
+
+	key, contentType, found := findHeader(response.Headers, "Content-Type")
+	if !found {
+		contentType, _ = preferredMediaType(declared.Content)
+	}
+	mediaType := declared.Content.Get(contentType)
+	if mediaType == nil {
+		property, header := "body", ""
+		if found {
+			property, header = "header", key
+		}
+		return []Mismatch{{Property: property, Header: header, Message: "Content type " + contentType + " is not declared"}}
+	}
+	if !isJSON(contentType) || mediaType.Schema == nil || mediaType.Schema.Value == nil {
+		return nil
+	}
+	return checkJSON(mediaType.Schema.Value, *response.Body)
+}
+
+// checkJSON checks that a body is JSON matching the schema.
+func checkJSON(schema *openapi3.Schema, raw string) []Mismatch {
+	var body any
+	if err := json.Unmarshal([]byte(raw), &body); err != nil {
+		return []Mismatch{{Property: "body", Message: "Body is not valid JSON: " + err.Error()}}
+	}
+	err := schema.VisitJSON(body, openapi3.MultiErrors(), openapi3.VisitAsResponse())
+	if err != nil {
+		return []Mismatch{{Property: "body", Message: "Body does not match the schema: " + schemaErrors(err)}}
+	}
+	return nil
+}
+
+// findHeader looks up a header case-insensitively and returns its key as written in the config.
+func findHeader(headers map[string]string, name string) (string, string, bool) {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return key, value, true
+		}
+	}
+	return "", "", false
+}
+
+// headerValue converts a header value to the JSON type expected by its schema.
+func headerValue(schema *openapi3.Schema, value string) any {
+	switch {
+	case schema.Type.Is(openapi3.TypeInteger), schema.Type.Is(openapi3.TypeNumber):
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	case schema.Type.Is(openapi3.TypeBoolean):
+		if boolean, err := strconv.ParseBool(value); err == nil {
+			return boolean
+		}
+	}
+	return value
+}
+
+// schemaErrors joins schema validation errors, each prefixed with the JSON pointer of the invalid value.
+func schemaErrors(err error) string {
+	var messages []string
+	var collect func(err error)
+	collect = func(err error) {
+		switch e := err.(type) { //nolint:errorlint
+		case openapi3.MultiError:
+			for _, nested := range e {
+				collect(nested)
+			}
+		case *openapi3.SchemaError:
+			if pointer := e.JSONPointer(); len(pointer) > 0 {
+				messages = append(messages, "/"+strings.Join(pointer, "/")+": "+e.Reason)
+				return
+			}
+			messages = append(messages, e.Reason)
+		default:
+			messages = append(messages, err.Error())
+		}
+	}
+	collect(err)
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,109 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const userAPI = `openapi: 3.0.3
+info:
+  title: Users
+  version: 1.0.0
+servers:
+  - url: /api
+paths:
+  /users/me:
+    get:
+      responses:
+        "204":
+          description: No content
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      responses:
+        "200":
+          description: User
+          headers:
+            X-Rate-Limit:
+              required: true
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id:
+                    type: integer
+                  name:
+                    type: string
+        4XX:
+          description: Error
+          content:
+            application/problem+json:
+              schema:
+                type: object
+`
+
+func checkUser(t *testing.T, method model.HTTPMethod, url string, response *model.ResponseBehavior) []Mismatch {
+	t.Helper()
+	doc, err := Load(writeSpec(t, userAPI))
+	require.NoError(t, err)
+	return CheckResponse(doc, method, url, response)
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func TestCheckResponse_Conforming(t *testing.T) {
+	assert.Empty(t, checkUser(t, model.MethodGet, "/api/users/{userId}", &model.ResponseBehavior{
+		Headers: map[string]string{"x-rate-limit": "10", "Content-Type": "application/json"},
+		Body:    ptr(`{"id":1,"name":"Ada"}`),
+	}))
+	assert.Empty(t, checkUser(t, model.MethodGet, "/api/users/42", &model.ResponseBehavior{
+		StatusCode: ptr(uint16(404)),
+		Headers:    map[string]string{"Content-Type": "application/problem+json"},
+		Body:       ptr(`{"title":"Not found"}`),
+	}))
+	assert.Empty(t, checkUser(t, model.MethodGet, "/api/users/me", &model.ResponseBehavior{StatusCode: ptr(uint16(204))}))
+	assert.Empty(t, checkUser(t, model.MethodWS, "/api/socket", &model.ResponseBehavior{}))
+	assert.Empty(t, checkUser(t, model.MethodGet, "/api/other", &model.ResponseBehavior{Proxy: ptr("http://localhost")}))
+}
+
+func TestCheckResponse_Mismatches(t *testing.T) {
+	assert.Equal(t, []Mismatch{{Message: "No operation POST /api/users/1 declared"}},
+		checkUser(t, model.MethodPost, "/api/users/1", &model.ResponseBehavior{}))
+
+	assert.Equal(t, []Mismatch{{Property: "status_code", Message: "Status code 200 is not declared for GET /users/me"}},
+		checkUser(t, model.MethodGet, "/api/users/me", &model.ResponseBehavior{}))
+
+	mismatches := checkUser(t, model.MethodGet, "/api/users/1", &model.ResponseBehavior{
+		Body: ptr(`{"name":1}`),
+	})
+	assert.Equal(t, []Mismatch{
+		{Message: "Required header X-Rate-Limit is missing"},
+		{Property: "body", Message: `Body does not match the schema: /name: value must be a string; /id: property "id" is missing`},
+	}, mismatches)
+
+	mismatches = checkUser(t, model.MethodGet, "/api/users/1", &model.ResponseBehavior{
+		Headers: map[string]string{"X-Rate-Limit": "many", "Content-Type": "text/plain"},
+		Body:    ptr("hello"),
+	})
+	assert.Equal(t, []Mismatch{
+		{Property: "header", Header: "X-Rate-Limit", Message: "Header X-Rate-Limit does not match its schema: value must be an integer"},
+		{Property: "header", Header: "Content-Type", Message: "Content type text/plain is not declared"},
+	}, mismatches)
+
+	assert.Equal(t, []Mismatch{{Property: "body", Message: "Response declares no body"}},
+		checkUser(t, model.MethodGet, "/api/users/me", &model.ResponseBehavior{StatusCode: ptr(uint16(204)), Body: ptr("x")}))
+}
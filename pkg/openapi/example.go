@@ -0,0 +1,172 @@
+package openapi
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxExampleDepth limits the nesting of generated examples, which also stops recursive schemas.
+const maxExampleDepth = 8
+
+// mediaTypeExample returns the example of a media type, the first named example
+// or a value generated from its schema.
+func mediaTypeExample(mediaType *openapi3.MediaType) any {
+	if mediaType.Example != nil {
+		return mediaType.Example
+	}
+	if value, ok := firstExample(mediaType.Examples); ok {
+		return value
+	}
+	if mediaType.Schema != nil {
+		return exampleValue(mediaType.Schema.Value)
+	}
+	return nil
+}
+
+// parameterExample returns the example of a header or parameter as string.
+func parameterExample(parameter *openapi3.Parameter) (string, bool) {
+	value := parameter.Example
+	if value == nil {
+		value, _ = firstExample(parameter.Examples)
+	}
+	if value == nil && parameter.Schema != nil && parameter.Schema.Value != nil {
+		schema := parameter.Schema.Value
+		switch {
+		case schema.Example != nil:
+			value = schema.Example
+		case schema.Default != nil:
+			value = schema.Default
+		case len(schema.Enum) > 0:
+			value = schema.Enum[0]
+		}
+	}
+	if value == nil {
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
+
+// firstExample returns the value of the first named example in alphabetical order.
+func firstExample(examples openapi3.Examples) (any, bool) {
+	names := make([]string, 0, len(examples))
+	for name, example := range examples {
+		if example != nil && example.Value != nil && example.Value.Value != nil {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, false
+	}
+	slices.Sort(names)
+	return examples[names[0]].Value.Value, true
+}
+
+// exampleValue generates a value that satisfies the schema, preferring declared examples,
+// defaults and enum values over generated placeholders.
+func exampleValue(schema *openapi3.Schema) any {
+	return example(schema, 0)
+}
+
+//nolint:cyclop,gocognit
+func example(schema *openapi3.Schema, depth int) any {
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.AllOf) > 0:
+		merged := map[string]any{}
+		for _, ref := range schema.AllOf {
+			if object, ok := example(ref.Value, depth+1).(map[string]any); ok {
+				for key, value := range object {
+					merged[key] = value
+				}
+			}
+		}
+		return merged
+	case len(schema.OneOf) > 0:
+		return example(schema.OneOf[0].Value, depth+1)
+	case len(schema.AnyOf) > 0:
+		return example(schema.AnyOf[0].Value, depth+1)
+	}
+
+	switch {
+	case schema.Type.Is(openapi3.TypeObject), schema.Type == nil && len(schema.Properties) > 0:
+		object := map[string]any{}
+		for name, ref := range schema.Properties {
+			if ref.Value == nil || ref.Value.WriteOnly {
+				continue
+			}
+			if value := example(ref.Value, depth+1); value != nil || slices.Contains(schema.Required, name) {
+				object[name] = value
+			}
+		}
+		return object
+	case schema.Type.Is(openapi3.TypeArray):
+		if schema.Items == nil {
+			return []any{}
+		}
+		item := example(schema.Items.Value, depth+1)
+		if item == nil {
+			return []any{}
+		}
+		items := []any{item}
+		for uint64(len(items)) < schema.MinItems {
+			items = append(items, item)
+		}
+		return items
+	case schema.Type.Is(openapi3.TypeString):
+		return stringExample(schema)
+	case schema.Type.Is(openapi3.TypeInteger):
+		if schema.Min != nil {
+			return int64(*schema.Min)
+		}
+		return 0
+	case schema.Type.Is(openapi3.TypeNumber):
+		if schema.Min != nil {
+			return *schema.Min
+		}
+		return 0.0
+	case schema.Type.Is(openapi3.TypeBoolean):
+		return true
+	}
+	return nil
+}
+
+// stringExample generates a placeholder matching common string formats.
+func stringExample(schema *openapi3.Schema) string {
+	value := "string"
+	switch schema.Format {
+	case "date-time":
+		value = "2024-01-01T00:00:00Z"
+	case "date":
+		value = "2024-01-01"
+	case "time":
+		value = "00:00:00"
+	case "email":
+		value = "user@example.com"
+	case "uuid":
+		value = "00000000-0000-0000-0000-000000000000"
+	case "uri", "url":
+		value = "https://example.com"
+	case "hostname":
+		value = "example.com"
+	case "ipv4":
+		value = "127.0.0.1"
+	case "ipv6":
+		value = "::1"
+	case "byte":
+		value = "c3RyaW5n"
+	}
+	for uint64(len(value)) < schema.MinLength {
+		value += "x"
+	}
+	return value
+}
@@ -0,0 +1,197 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const defaultStatusCode = http.StatusOK
+
+// Load reads and validates an OpenAPI 3 specification in YAML or JSON format.
+func Load(path string) (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	doc, err := loader.LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI spec: %w", err)
+	}
+	if err = doc.Validate(context.Background(), openapi3.DisableExamplesValidation()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+	return doc, nil
+}
+
+// Build constructs a BehaviorSet with one behavior per operation of the specification.
+// Paths keep their templates like /users/{id} and are prefixed with the base path of the first server.
+// Each behavior responds with the first success response of the operation, using its example
+// or a value generated from its schema.
+func Build(doc *openapi3.T) (*model.BehaviorSet, error) {
+	bs := &model.BehaviorSet{Behaviors: []*model.Behavior{}}
+	basePath := basePath(doc)
+
+	for _, path := range doc.Paths.InMatchingOrder() {
+		item := doc.Paths.Value(path)
+		operations := item.Operations()
+		methods := make([]string, 0, len(operations))
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		slices.Sort(methods)
+
+		for _, name := range methods {
+			method, match := model.HTTPMethodFromString(name)
+			if !match {
+				continue
+			}
+			responseBehavior, err := buildResponse(operations[name])
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", name, path, err)
+			}
+			bs.Behaviors = append(bs.Behaviors, &model.Behavior{
+				Method:           method,
+				URL:              basePath + path,
+				ResponseBehavior: responseBehavior,
+			})
+		}
+	}
+
+	return bs, nil
+}
+
+// basePath returns the path of the first server without trailing slash, e.g. /v1 for https://api.example.com/v1/.
+func basePath(doc *openapi3.T) string {
+	if len(doc.Servers) == 0 {
+		return ""
+	}
+	path, err := doc.Servers[0].BasePath()
+	if err != nil || path == "/" {
+		return ""
+	}
+	return strings.TrimSuffix(path, "/")
+}
+
+// buildResponse creates the response behavior of an operation.
+func buildResponse(operation *openapi3.Operation) (*model.ResponseBehavior, error) {
+	responseBehavior := &model.ResponseBehavior{}
+	status, response := preferredResponse(operation)
+	statusCode := uint16(status) //nolint:gosec
+	responseBehavior.StatusCode = &statusCode
+	if response == nil {
+		return responseBehavior, nil
+	}
+
+	for name, headerRef := range response.Headers {
+		if headerRef.Value == nil {
+			continue
+		}
+		if value, ok := parameterExample(&headerRef.Value.Parameter); ok {
+			if responseBehavior.Headers == nil {
+				responseBehavior.Headers = make(map[string]string)
+			}
+			responseBehavior.Headers[name] = value
+		}
+	}
+
+	contentType, mediaType := preferredMediaType(response.Content)
+	if mediaType == nil {
+		return responseBehavior, nil
+	}
+	if responseBehavior.Headers == nil {
+		responseBehavior.Headers = make(map[string]string)
+	}
+	responseBehavior.Headers["Content-Type"] = contentType
+
+	example := mediaTypeExample(mediaType)
+	if example == nil {
+		return responseBehavior, nil
+	}
+	if text, ok := example.(string); ok && !isJSON(contentType) {
+		responseBehavior.Body = &text
+		return responseBehavior, nil
+	}
+	body, err := json.Marshal(example)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode example: %w", err)
+	}
+	responseBody := string(body)
+	responseBehavior.Body = &responseBody
+	return responseBehavior, nil
+}
+
+// preferredResponse returns the lowest declared 2xx response of an operation,
+// falling back to the default response and then to the lowest declared status code.
+// Status ranges like 2XX resolve to their lowest code.
+func preferredResponse(operation *openapi3.Operation) (int, *openapi3.Response) {
+	if operation.Responses == nil {
+		return defaultStatusCode, nil
+	}
+
+	best, bestStatus := (*openapi3.ResponseRef)(nil), 0
+	for key, ref := range operation.Responses.Map() {
+		status, ok := statusFromKey(key)
+		if !ok {
+			continue
+		}
+		success := status >= 200 && status < 300
+		bestSuccess := bestStatus >= 200 && bestStatus < 300
+		if best == nil || (success && !bestSuccess) || (success == bestSuccess && status < bestStatus) {
+			best, bestStatus = ref, status
+		}
+	}
+	if best != nil && (bestStatus < 300 || operation.Responses.Default() == nil) {
+		return bestStatus, best.Value
+	}
+	if ref := operation.Responses.Default(); ref != nil {
+		return defaultStatusCode, ref.Value
+	}
+	return defaultStatusCode, nil
+}
+
+// statusFromKey converts a response key like 404 or 4XX to a status code.
+func statusFromKey(key string) (int, bool) {
+	key = strings.ToUpper(key)
+	if len(key) == 3 && strings.HasSuffix(key, "XX") {
+		key = key[:1] + "00"
+	}
+	status, err := strconv.Atoi(key)
+	if err != nil || status < 100 || status > 599 {
+		return 0, false
+	}
+	return status, true
+}
+
+// preferredMediaType returns the JSON media type if declared, otherwise the first one by name.
+func preferredMediaType(content openapi3.Content) (string, *openapi3.MediaType) {
+	if mediaType, ok := content["application/json"]; ok {
+		return "application/json", mediaType
+	}
+	names := make([]string, 0, len(content))
+	for name := range content {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if isJSON(name) {
+			return name, content[name]
+		}
+	}
+	if len(names) > 0 {
+		return names[0], content[names[0]]
+	}
+	return "", nil
+}
+
+// isJSON reports whether the content type is JSON, including vendor types like application/problem+json.
+func isJSON(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
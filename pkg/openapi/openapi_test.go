@@ -0,0 +1,176 @@
+package openapi
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petStore = `openapi: 3.0.3
+info:
+  title: Pet Store
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: All pets
+          headers:
+            X-Total-Count:
+              schema:
+                type: integer
+                example: 2
+          content:
+            application/json:
+              examples:
+                b-second:
+                  value: [{"id": 2}]
+                a-first:
+                  value: [{"id": 1, "name": "Rex"}]
+    post:
+      responses:
+        "400":
+          description: Invalid
+        "201":
+          description: Created
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pet"
+  /pets/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    delete:
+      responses:
+        default:
+          description: Unexpected
+          content:
+            text/plain:
+              example: gone
+    get:
+      responses:
+        "404":
+          description: Not found
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+          minimum: 1
+        name:
+          type: string
+        born:
+          type: string
+          format: date
+        tags:
+          type: array
+          items:
+            type: string
+            enum: [cute, loud]
+        kind:
+          oneOf:
+            - type: string
+              default: dog
+            - type: integer
+        owner:
+          $ref: "#/components/schemas/Owner"
+    Owner:
+      type: object
+      properties:
+        email:
+          type: string
+          format: email
+        password:
+          type: string
+          writeOnly: true
+        pets:
+          type: array
+          items:
+            $ref: "#/components/schemas/Pet"
+`
+
+func writeSpec(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func findBehavior(t *testing.T, bs *model.BehaviorSet, method model.HTTPMethod, url string) *model.Behavior {
+	t.Helper()
+	for _, behavior := range bs.Behaviors {
+		if behavior.Method == method && behavior.URL == url {
+			return behavior
+		}
+	}
+	require.Failf(t, "behavior not found", "%s %s", method, url)
+	return nil
+}
+
+func TestBuild(t *testing.T) {
+	doc, err := Load(writeSpec(t, petStore))
+	require.NoError(t, err)
+	bs, err := Build(doc)
+	require.NoError(t, err)
+	assert.Len(t, bs.Behaviors, 4)
+
+	list := findBehavior(t, bs, model.MethodGet, "/v1/pets")
+	assert.Equal(t, uint16(http.StatusOK), *list.StatusCode)
+	assert.JSONEq(t, `[{"id":1,"name":"Rex"}]`, *list.Body)
+	assert.Equal(t, map[string]string{"Content-Type": "application/json", "X-Total-Count": "2"}, list.Headers)
+
+	create := findBehavior(t, bs, model.MethodPost, "/v1/pets")
+	assert.Equal(t, uint16(http.StatusCreated), *create.StatusCode)
+	assert.JSONEq(t, `{
+		"id": 1,
+		"name": "string",
+		"born": "2024-01-01",
+		"tags": ["cute"],
+		"kind": "dog",
+		"owner": {"email": "user@example.com", "pets": [{
+			"id": 1, "name": "string", "born": "2024-01-01", "tags": ["cute"], "kind": "dog",
+			"owner": {"email": "user@example.com", "pets": [{
+				"id": 1, "name": "string", "born": "2024-01-01", "tags": ["cute"], "kind": "dog",
+				"owner": {"email": "user@example.com", "pets": []}
+			}]}
+		}]}
+	}`, *create.Body)
+
+	remove := findBehavior(t, bs, model.MethodDelete, "/v1/pets/{id}")
+	assert.Equal(t, uint16(http.StatusOK), *remove.StatusCode)
+	assert.Equal(t, "gone", *remove.Body)
+	assert.Equal(t, "text/plain", remove.Headers["Content-Type"])
+
+	get := findBehavior(t, bs, model.MethodGet, "/v1/pets/{id}")
+	assert.Equal(t, uint16(http.StatusNotFound), *get.StatusCode)
+	assert.Nil(t, get.Body)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+
+	_, err = Load(writeSpec(t, "openapi: 3.0.3\ninfo:\n  title: Broken\npaths: {}\n"))
+	require.Error(t, err)
+}
+
+func TestIsJSON(t *testing.T) {
+	assert.True(t, isJSON("application/json; charset=utf-8"))
+	assert.True(t, isJSON("application/problem+json"))
+	assert.False(t, isJSON("text/plain"))
+}
@@ -0,0 +1,39 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// LoadSchema reads a JSON Schema from a JSON file.
+// The keywords of OpenAPI 3.0 schemas are supported, so `nullable` replaces the `null` type,
+// and references (`$ref`) are not.
+func LoadSchema(path string) (*openapi3.Schema, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JSON Schema: %w", err)
+	}
+	schema := &openapi3.Schema{}
+	if err = json.Unmarshal(raw, schema); err != nil {
+		return nil, fmt.Errorf("failed to load JSON Schema: %w", err)
+	}
+	if err = schema.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid JSON Schema: %w", err)
+	}
+	return schema, nil
+}
+
+// CheckSchema compares the body of a response behavior with a JSON Schema.
+// Responses without body and the ones CheckResponse does not check are skipped.
+func CheckSchema(schema *openapi3.Schema, response *model.ResponseBehavior) []Mismatch {
+	if response.Body == nil || response.Proxy != nil || response.Redirect != nil || response.SSE ||
+		response.Stream != nil || response.WebSocket != nil || response.GRPC != nil || response.GraphQL != nil {
+		return nil
+	}
+	return checkJSON(schema, *response.Body)
+}
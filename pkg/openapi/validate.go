@@ -0,0 +1,105 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// Validator checks incoming requests against the operations of an OpenAPI spec.
+type Validator struct {
+	router  routers.Router
+	options *openapi3filter.Options
+}
+
+// NewValidator creates a Validator for the spec.
+// Requests are routed by path only, so hosts and schemes of the declared servers are ignored.
+func NewValidator(doc *openapi3.T) (*Validator, error) {
+	routed := *doc
+	routed.Servers = nil
+	if path := basePath(doc); path != "" {
+		routed.Servers = openapi3.Servers{{URL: path}}
+	}
+	router, err := gorillamux.NewRouter(&routed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create router: %w", err)
+	}
+
+	options := &openapi3filter.Options{
+		MultiError:          true,
+		SkipSettingDefaults: true,
+		AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+	}
+	options.WithCustomSchemaErrorFunc(func(err *openapi3.SchemaError) string {
+		if pointer := err.JSONPointer(); len(pointer) > 0 {
+			return "/" + strings.Join(pointer, "/") + ": " + err.Reason
+		}
+		return err.Reason
+	})
+
+	return &Validator{router: router, options: options}, nil
+}
+
+// Validate returns the violations of the request against its operation, like invalid path parameters,
+// query parameters, headers or body. Requests without matching operation are a violation too.
+// The request body is restored, so the request can still be served after validation.
+func (v *Validator) Validate(r *http.Request) []string {
+	route, pathParams, err := v.router.FindRoute(r)
+	if err != nil {
+		return []string{fmt.Sprintf("%s %s: %s", r.Method, r.URL.Path, err.Error())}
+	}
+
+	err = openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    v.options,
+	})
+	if err == nil {
+		return nil
+	}
+	return violations(err)
+}
+
+// violations flattens validation errors into one message per violation,
+// each prefixed with the parameter or body it concerns.
+func violations(err error) []string {
+	switch e := err.(type) { //nolint:errorlint
+	case openapi3.MultiError:
+		var messages []string
+		for _, nested := range e {
+			messages = append(messages, violations(nested)...)
+		}
+		return messages
+	case *openapi3filter.RequestError:
+		messages := []string{e.Reason}
+		if e.Err != nil {
+			messages = violations(e.Err)
+			if e.Reason != "" {
+				for i, message := range messages {
+					messages[i] = e.Reason + ": " + message
+				}
+			}
+		}
+
+		subject := ""
+		switch {
+		case e.Parameter != nil:
+			subject = fmt.Sprintf("%s parameter %q", e.Parameter.In, e.Parameter.Name)
+		case e.RequestBody != nil:
+			subject = "request body"
+		}
+		if subject != "" {
+			for i, message := range messages {
+				messages[i] = subject + ": " + message
+			}
+		}
+		return messages
+	}
+	return []string{err.Error()}
+}
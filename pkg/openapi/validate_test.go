@@ -0,0 +1,102 @@
+package openapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderAPI = `openapi: 3.0.3
+info:
+  title: Orders
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /orders/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    put:
+      parameters:
+        - name: dry_run
+          in: query
+          schema:
+            type: boolean
+        - name: X-Tenant
+          in: header
+          required: true
+          schema:
+            type: string
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [amount]
+              properties:
+                amount:
+                  type: integer
+                  minimum: 1
+                note:
+                  type: string
+      responses:
+        "204":
+          description: Updated
+`
+
+func newOrderValidator(t *testing.T) *Validator {
+	t.Helper()
+	doc, err := Load(writeSpec(t, orderAPI))
+	require.NoError(t, err)
+	validator, err := NewValidator(doc)
+	require.NoError(t, err)
+	return validator
+}
+
+func TestValidator_Valid(t *testing.T) {
+	validator := newOrderValidator(t)
+	req := httptest.NewRequest(http.MethodPut, "/v1/orders/7?dry_run=true", strings.NewReader(`{"amount":3}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant", "acme")
+
+	assert.Empty(t, validator.Validate(req))
+
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":3}`, string(body))
+}
+
+func TestValidator_Violations(t *testing.T) {
+	validator := newOrderValidator(t)
+	req := httptest.NewRequest(http.MethodPut, "/v1/orders/abc?dry_run=maybe", strings.NewReader(`{"amount":0,"note":1}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	violations := validator.Validate(req)
+	assert.Len(t, violations, 5)
+	assert.Contains(t, violations[0], `path parameter "id"`)
+	assert.Contains(t, violations[1], `query parameter "dry_run"`)
+	assert.Contains(t, violations[2], `header parameter "X-Tenant"`)
+	assert.Contains(t, strings.Join(violations[3:], "\n"), "request body: doesn't match schema: /amount: number must be at least 1")
+	assert.Contains(t, strings.Join(violations[3:], "\n"), "request body: doesn't match schema: /note: value must be a string")
+}
+
+func TestValidator_UnknownOperation(t *testing.T) {
+	validator := newOrderValidator(t)
+
+	violations := validator.Validate(httptest.NewRequest(http.MethodGet, "/v1/orders/7", nil))
+	require.Len(t, violations, 1)
+	assert.Contains(t, violations[0], "GET /v1/orders/7")
+
+	violations = validator.Validate(httptest.NewRequest(http.MethodGet, "/orders", nil))
+	assert.Len(t, violations, 1)
+}
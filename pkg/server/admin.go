@@ -0,0 +1,41 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// adminPathPrefix is reserved for endpoints controlling the mock server itself.
+const adminPathPrefix = "/__servmock/"
+
+// handleAdmin serves the control endpoints of the mock server.
+//
+//	POST /__servmock/sequences/reset[?method=GET&path=/job/1]
+//	GET  /__servmock/status
+func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimPrefix(r.URL.Path, adminPathPrefix) {
+	case "status":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, s.ReloadStatus())
+	case "sequences/reset":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		query := r.URL.Query()
+		reset := s.resetSequences(strings.ToUpper(query.Get("method")), query.Get("path"))
+		writeJSON(w, http.StatusOK, map[string]int{"reset": reset})
+	default:
+		http.Error(w, "Not Found", http.StatusNotFound)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,6 @@
+package server
+
+import "errors"
+
+// ErrNoDescriptors indicates a gRPC call without loaded protobuf descriptors.
+var ErrNoDescriptors = errors.New("no protobuf descriptor set loaded")
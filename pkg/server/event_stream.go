@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+// writeEventStream serves the behavior as Server-Sent Events.
+// Without declared events, every declared chunk or else every line of the body
+// is sent as a data-only event.
+//
+//nolint:cyclop
+func writeEventStream(w http.ResponseWriter, r *http.Request, statusCode int, behavior *model.ResponseBehavior) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	stream := behavior.EventStream
+	if stream == nil {
+		stream = &model.EventStream{}
+	}
+	events := stream.Events
+	if len(events) == 0 && behavior.Stream != nil {
+		for _, chunk := range behavior.Stream.Chunks {
+			events = append(events, &model.Event{Data: []string{chunk.Data}, Delay: chunk.Delay})
+		}
+	}
+	if len(events) == 0 && behavior.Body != nil {
+		for _, line := range strings.Split(*behavior.Body, "\n") {
+			events = append(events, &model.Event{Data: []string{line}})
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(statusCode)
+	flusher.Flush()
+
+	interval := stream.Interval
+	if interval == nil {
+		interval = behavior.ChunkDelay
+	}
+
+	ctx := r.Context()
+	start := resumeIndex(events, r.Header.Get("Last-Event-ID"), stream.Loop)
+	for sent := 0; len(events) > 0 && (stream.Loop || start < len(events)); sent++ {
+		event := events[start%len(events)]
+		start++
+
+		pause := time.Duration(0)
+		if event.Delay != nil {
+			pause = *event.Delay
+		} else if sent > 0 && interval != nil {
+			pause = *interval
+		}
+		if !waitWithKeepAlive(ctx, w, pause, stream.KeepAlive) {
+			return
+		}
+		if !writeThrottled(ctx, w, []byte(formatEvent(event)), behavior) {
+			return
+		}
+	}
+
+	// Keep the connection open after the last event, e.g. for notification feeds.
+	for hold := stream.Hold; hold; {
+		hold = waitWithKeepAlive(ctx, w, time.Hour, stream.KeepAlive)
+	}
+}
+
+// resumeIndex returns the index of the first event to send to honor the Last-Event-ID of a reconnecting client.
+func resumeIndex(events []*model.Event, lastEventID string, loop bool) int {
+	if lastEventID == "" {
+		return 0
+	}
+	for i, event := range events {
+		if event.ID != nil && *event.ID == lastEventID {
+			if loop {
+				return (i + 1) % len(events)
+			}
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// formatEvent renders an event in the text/event-stream format.
+func formatEvent(event *model.Event) string {
+	sb := strings.Builder{}
+	if event.Type != "" {
+		sb.WriteString("event: " + event.Type + "\n")
+	}
+	if event.ID != nil {
+		sb.WriteString("id: " + *event.ID + "\n")
+	}
+	if event.Retry != nil {
+		sb.WriteString("retry: " + strconv.FormatUint(uint64(*event.Retry), 10) + "\n")
+	}
+	for _, data := range event.Data {
+		for _, line := range strings.Split(data, "\n") {
+			sb.WriteString("data: " + line + "\n")
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// waitWithKeepAlive waits for the given duration while sending a comment ping every keep-alive interval.
+func waitWithKeepAlive(ctx context.Context, w http.ResponseWriter, d time.Duration, keepAlive *time.Duration) bool {
+	if keepAlive == nil {
+		return sleep(ctx, d)
+	}
+
+	flusher, _ := w.(http.Flusher)
+	for d > *keepAlive {
+		if !sleep(ctx, *keepAlive) {
+			return false
+		}
+		if _, err := w.Write([]byte(": ping\n\n")); err != nil {
+			return false
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		d -= *keepAlive
+	}
+	return sleep(ctx, d)
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEventStreamServer(stream *model.EventStream) *mockServer {
+	beh := &model.Behavior{
+		Method:           http.MethodGet,
+		URL:              "/events",
+		ResponseBehavior: &model.ResponseBehavior{SSE: true, EventStream: stream},
+	}
+	return newTestServer([]*model.Behavior{beh}, nil)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestEventStream_StructuredEvents(t *testing.T) {
+	retry := uint(3000)
+	ts := newEventStreamServer(&model.EventStream{Events: []*model.Event{
+		{Type: "message", ID: strPtr("1"), Data: []string{"hello", "world"}, Retry: &retry},
+		{ID: strPtr("2"), Data: []string{`{"done":true}`}},
+	}})
+	r := httptest.NewRequest(http.MethodGet, "/events", nil)
+	w := httptest.NewRecorder()
+	ts.handleRequest(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t,
+		"event: message\nid: 1\nretry: 3000\ndata: hello\ndata: world\n\nid: 2\ndata: {\"done\":true}\n\n",
+		w.Body.String())
+}
+
+func TestEventStream_LastEventID(t *testing.T) {
+	ts := newEventStreamServer(&model.EventStream{Events: []*model.Event{
+		{ID: strPtr("1"), Data: []string{"a"}},
+		{ID: strPtr("2"), Data: []string{"b"}},
+		{ID: strPtr("3"), Data: []string{"c"}},
+	}})
+	r := httptest.NewRequest(http.MethodGet, "/events", nil)
+	r.Header.Set("Last-Event-ID", "2")
+	w := httptest.NewRecorder()
+	ts.handleRequest(w, r)
+	assert.Equal(t, "id: 3\ndata: c\n\n", w.Body.String())
+}
+
+func TestEventStream_IntervalAndKeepAlive(t *testing.T) {
+	interval := 120 * time.Millisecond
+	keepAlive := 50 * time.Millisecond
+	ts := newEventStreamServer(&model.EventStream{
+		Interval:  &interval,
+		KeepAlive: &keepAlive,
+		Events:    []*model.Event{{Data: []string{"a"}}, {Data: []string{"b"}}},
+	})
+	r := httptest.NewRequest(http.MethodGet, "/events", nil)
+	w := httptest.NewRecorder()
+	start := time.Now()
+	ts.handleRequest(w, r)
+	assert.GreaterOrEqual(t, time.Since(start), interval)
+	assert.Equal(t, "data: a\n\n: ping\n\n: ping\n\ndata: b\n\n", w.Body.String())
+}
+
+func TestEventStream_Loop(t *testing.T) {
+	interval := 10 * time.Millisecond
+	srv := httptest.NewServer(http.HandlerFunc(newEventStreamServer(&model.EventStream{
+		Interval: &interval,
+		Loop:     true,
+		Events:   []*model.Event{{ID: strPtr("1"), Data: []string{"a"}}, {ID: strPtr("2"), Data: []string{"b"}}},
+	}).handleRequest))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	data := []string{}
+	for len(data) < 5 && scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "data: ") {
+			data = append(data, strings.TrimPrefix(line, "data: "))
+		}
+	}
+	assert.Equal(t, []string{"a", "b", "a", "b", "a"}, data)
+}
+
+func TestEventStream_Hold(t *testing.T) {
+	ts := newEventStreamServer(&model.EventStream{Hold: true, Events: []*model.Event{{Data: []string{"a"}}}})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	r := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	start := time.Now()
+	ts.handleRequest(w, r)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, "data: a\n\n", w.Body.String())
+}
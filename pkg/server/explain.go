@@ -0,0 +1,209 @@
+package server
+
+import (
+	"log"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+// MatchHeader names the behavior that served a request. If none matched, it is `default`, or `none`
+// if there is no default behavior. Batched GraphQL requests get one per operation.
+const MatchHeader = "X-ServMock-Match"
+
+// NearMissHeader names a behavior that almost matched an unmatched request
+// and why it was rejected. It is set once per near miss.
+const NearMissHeader = "X-ServMock-Near-Miss"
+
+// ExplainMode tells how match decisions are explained.
+type ExplainMode string
+
+const (
+	// ExplainOff does not explain match decisions.
+	ExplainOff ExplainMode = "off"
+	// ExplainHeader explains match decisions in the MatchHeader and NearMissHeader response headers.
+	ExplainHeader ExplainMode = "header"
+	// ExplainLog explains match decisions in a log line per request.
+	ExplainLog ExplainMode = "log"
+)
+
+// matchExplanation is the behavior that matched a request or a GraphQL operation of it or,
+// if none did, the behaviors that almost matched and whether the default behavior served it.
+type matchExplanation struct {
+	mode       ExplainMode
+	operation  string
+	matched    *model.Behavior
+	fallback   bool
+	nearMisses []nearMiss
+}
+
+// nearMiss is a behavior whose path is close to the one of a request it did not match.
+type nearMiss struct {
+	behavior *model.Behavior
+	reasons  []string
+}
+
+// SetExplainMode sets how match decisions are explained, e.g. to debug why a request got the default response.
+func (s *Server) SetExplainMode(mode ExplainMode) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.explainMode = mode
+}
+
+// explanation returns how a request was matched, or nil if match decisions are not explained.
+// nearMisses is only called if no behavior matched. The caller must hold s.mutex.
+func (s *Server) explanation(matched *model.Behavior, fallback bool, nearMisses func() []nearMiss) *matchExplanation {
+	if s.explainMode != ExplainHeader && s.explainMode != ExplainLog {
+		return nil
+	}
+	explanation := &matchExplanation{mode: s.explainMode, matched: matched}
+	if matched == nil {
+		explanation.fallback = fallback
+		explanation.nearMisses = nearMisses()
+	}
+	return explanation
+}
+
+// nearMisses returns the behaviors that almost matched a request. The caller must hold s.mutex.
+func (s *Server) nearMisses(r *http.Request, body *string) []nearMiss {
+	var nearMisses []nearMiss
+	for _, behavior := range s.behaviorSet.Behaviors {
+		if reasons := rejectionReasons(behavior, r, body); len(reasons) > 0 {
+			nearMisses = append(nearMisses, nearMiss{behavior: behavior, reasons: reasons})
+		}
+	}
+	return nearMisses
+}
+
+// rejectionReasons returns why a behavior with a path close to the one of the request did not match it.
+// Behaviors with unrelated paths have no reasons.
+func rejectionReasons(behavior *model.Behavior, r *http.Request, body *string) []string {
+	var reasons []string
+	if !pathMatches(behavior.URL, r.URL.Path) {
+		pattern, path := strings.TrimSuffix(behavior.URL, "/"), strings.TrimSuffix(r.URL.Path, "/")
+		if !pathMatches(strings.ToLower(pattern), strings.ToLower(path)) {
+			return nil
+		}
+		if strings.HasSuffix(behavior.URL, "/") != strings.HasSuffix(r.URL.Path, "/") {
+			reasons = append(reasons, "trailing slash differs")
+		}
+		if !pathMatches(pattern, path) {
+			reasons = append(reasons, "path case differs")
+		}
+	}
+
+	if !methodMatches(behavior.Method, r) {
+		reasons = append(reasons, "method "+string(behavior.Method)+" differs from "+r.Method)
+	}
+	if behavior.Query != nil && !queryEquals(behavior.Query, r.URL.Query()) {
+		if len(behavior.Query) == 0 {
+			reasons = append(reasons, "query predicate failed, expected no query")
+		} else {
+			reasons = append(reasons, "query predicate failed, expected "+behavior.Query.Encode())
+		}
+	}
+	if behavior.RequestBody != nil && (body == nil || !bodyEquals(*behavior.RequestBody, *body)) {
+		reasons = append(reasons, "body predicate failed")
+	}
+	return reasons
+}
+
+// graphQLNearMisses returns the GRAPHQL behaviors of the path that did not match an operation.
+// The caller must hold s.mutex.
+func (s *Server) graphQLNearMisses(path string, operation *graphQLOperation) []nearMiss {
+	var nearMisses []nearMiss
+	for _, behavior := range s.behaviorSet.Behaviors {
+		if behavior.Method != model.MethodGraphQL || !pathMatches(behavior.URL, path) {
+			continue
+		}
+		if reasons := graphQLRejectionReasons(behavior.GraphQLMatch, operation); len(reasons) > 0 {
+			nearMisses = append(nearMisses, nearMiss{behavior: behavior, reasons: reasons})
+		}
+	}
+	return nearMisses
+}
+
+// graphQLRejectionReasons returns why an operation does not satisfy the criteria of a match.
+func graphQLRejectionReasons(match *model.GraphQLMatch, operation *graphQLOperation) []string {
+	if match == nil {
+		return nil
+	}
+	var reasons []string
+	if match.OperationName != nil && *match.OperationName != operation.name {
+		reasons = append(reasons, "operation "+*match.OperationName+" differs from "+graphQLOperationName(operation))
+	}
+	if match.OperationType != nil && *match.OperationType != operation.operationType {
+		reasons = append(reasons, "type "+string(*match.OperationType)+" differs from "+string(operation.operationType))
+	}
+	for _, name := range slices.Sorted(maps.Keys(match.Variables)) {
+		actual, ok := operation.Variables[name]
+		if !ok || !reflect.DeepEqual(match.Variables[name], actual) {
+			reasons = append(reasons, "variable "+name+" differs")
+		}
+	}
+	return reasons
+}
+
+// explain reports the match decision of a request as configured by the explain mode.
+func explain(w http.ResponseWriter, r *http.Request, explanation *matchExplanation) {
+	request := r.Method + " " + r.URL.Path
+	if explanation.operation != "" {
+		request += " operation " + explanation.operation
+	}
+
+	if explanation.matched != nil {
+		match := describeBehavior(explanation.matched)
+		if explanation.mode == ExplainHeader {
+			w.Header().Add(MatchHeader, match)
+			return
+		}
+		log.Printf("Request %s matched %s", request, match)
+		return
+	}
+
+	nearMisses := make([]string, 0, len(explanation.nearMisses))
+	for _, miss := range explanation.nearMisses {
+		nearMisses = append(nearMisses, describeBehavior(miss.behavior)+": "+strings.Join(miss.reasons, ", "))
+	}
+	if explanation.mode == ExplainHeader {
+		match := "none"
+		if explanation.fallback {
+			match = "default"
+		}
+		w.Header().Add(MatchHeader, match)
+		for _, miss := range nearMisses {
+			w.Header().Add(NearMissHeader, miss)
+		}
+		return
+	}
+
+	message := "Request " + request + " matched no behavior"
+	if explanation.fallback {
+		message += " and got the default response"
+	}
+	if len(nearMisses) > 0 {
+		message += ", near misses: " + strings.Join(nearMisses, "; ")
+	}
+	log.Print(message)
+}
+
+// describeBehavior names a behavior by its section and where it was defined, like `[GET /users] at line 3 of mocks.ini`.
+func describeBehavior(behavior *model.Behavior) string {
+	section := behavior.Origin.Section
+	if section == "" {
+		section = string(behavior.Method) + " " + behavior.URL
+	}
+	description := "[" + section + "]"
+	if behavior.Origin.Line > 0 {
+		description += " at line " + strconv.FormatUint(behavior.Origin.Line, 10)
+	}
+	if behavior.Origin.Source != "" {
+		description += " of " + behavior.Origin.Source
+	}
+	return description
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newExplainServer(mode ExplainMode) *mockServer {
+	body := `{"name": "Rex"}`
+	ts := newTestServer([]*model.Behavior{
+		{
+			Method:           http.MethodGet,
+			URL:              "/users",
+			ResponseBehavior: &model.ResponseBehavior{},
+			Origin:           model.Origin{Section: "GET /users", Source: "mocks.ini", Line: 3},
+		},
+		{
+			Method:           http.MethodPost,
+			URL:              "/users/",
+			ResponseBehavior: &model.ResponseBehavior{},
+			Origin:           model.Origin{Section: "POST /users/", Source: "mocks.ini", Line: 6},
+		},
+		{
+			Method:           http.MethodGet,
+			URL:              "/search",
+			Query:            url.Values{"page": {"2"}},
+			RequestBody:      &body,
+			ResponseBehavior: &model.ResponseBehavior{},
+		},
+		{
+			Method:           http.MethodGet,
+			URL:              "/orders",
+			ResponseBehavior: &model.ResponseBehavior{},
+		},
+	}, &model.ResponseBehavior{StatusCode: ptr(uint16(http.StatusTeapot))})
+	ts.SetExplainMode(mode)
+	return ts
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestExplain_HeaderMatch(t *testing.T) {
+	ts := newExplainServer(ExplainHeader)
+	w := httptest.NewRecorder()
+	ts.handleRequest(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[GET /users] at line 3 of mocks.ini", w.Header().Get(MatchHeader))
+	assert.Empty(t, w.Header().Values(NearMissHeader))
+}
+
+func TestExplain_HeaderNearMisses(t *testing.T) {
+	ts := newExplainServer(ExplainHeader)
+	w := httptest.NewRecorder()
+	ts.handleRequest(w, httptest.NewRequest(http.MethodPost, "/Users", nil))
+	assert.Equal(t, http.StatusTeapot, w.Code)
+	assert.Equal(t, "default", w.Header().Get(MatchHeader))
+	assert.Equal(t, []string{
+		"[GET /users] at line 3 of mocks.ini: path case differs, method GET differs from POST",
+		"[POST /users/] at line 6 of mocks.ini: trailing slash differs, path case differs",
+	}, w.Header().Values(NearMissHeader))
+
+	w = httptest.NewRecorder()
+	ts.handleRequest(w, httptest.NewRequest(http.MethodGet, "/search?page=3", strings.NewReader(`{"name":"Max"}`)))
+	assert.Equal(t, []string{
+		"[GET /search]: query predicate failed, expected page=2, body predicate failed",
+	}, w.Header().Values(NearMissHeader))
+}
+
+func TestExplain_Log(t *testing.T) {
+	var out bytes.Buffer
+	previous := log.Writer()
+	log.SetOutput(&out)
+	t.Cleanup(func() { log.SetOutput(previous) })
+
+	ts := newExplainServer(ExplainLog)
+	w := httptest.NewRecorder()
+	ts.handleRequest(w, httptest.NewRequest(http.MethodGet, "/users/", nil))
+	assert.Empty(t, w.Header().Get(MatchHeader))
+	assert.Contains(t, out.String(), "Request GET /users/ matched no behavior and got the default response, near misses: "+
+		"[GET /users] at line 3 of mocks.ini: trailing slash differs; "+
+		"[POST /users/] at line 6 of mocks.ini: method POST differs from GET")
+
+	out.Reset()
+	ts.handleRequest(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+	assert.Contains(t, out.String(), "Request GET /orders matched [GET /orders]")
+}
+
+func TestExplain_Off(t *testing.T) {
+	ts := newExplainServer(ExplainOff)
+	w := httptest.NewRecorder()
+	ts.handleRequest(w, httptest.NewRequest(http.MethodGet, "/users/", nil))
+	assert.Empty(t, w.Header().Get(MatchHeader))
+	assert.Empty(t, w.Header().Values(NearMissHeader))
+}
+
+func TestExplain_NoDefault(t *testing.T) {
+	var out bytes.Buffer
+	previous := log.Writer()
+	log.SetOutput(&out)
+	t.Cleanup(func() { log.SetOutput(previous) })
+
+	ts := newTestServer(nil, nil)
+	ts.SetExplainMode(ExplainHeader)
+	w := httptest.NewRecorder()
+	ts.handleRequest(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "none", w.Header().Get(MatchHeader))
+
+	ts.SetExplainMode(ExplainLog)
+	ts.handleRequest(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Contains(t, out.String(), "Request GET /users matched no behavior\n")
+}
+
+func TestExplain_GraphQL(t *testing.T) {
+	var out bytes.Buffer
+	previous := log.Writer()
+	log.SetOutput(&out)
+	t.Cleanup(func() { log.SetOutput(previous) })
+
+	mutation := model.GraphQLMutation
+	getUser := graphQLBehavior(
+		&model.GraphQLMatch{OperationName: strPtr("GetUser"), Variables: map[string]any{"id": float64(1)}},
+		&model.GraphQLResponse{Data: strPtr(`{"user":null}`)},
+	)
+	getUser.Origin = model.Origin{Section: "GRAPHQL /graphql", Line: 1}
+	save := graphQLBehavior(&model.GraphQLMatch{OperationType: &mutation}, &model.GraphQLResponse{Data: strPtr(`{"ok":true}`)})
+	save.Origin = model.Origin{Section: "GRAPHQL /graphql", Line: 5}
+	ts := newTestServer([]*model.Behavior{getUser, save}, nil)
+	ts.SetExplainMode(ExplainHeader)
+
+	rr := postGraphQL(ts, `{"query":"mutation Save { save }"}`)
+	assert.Equal(t, []string{"[GRAPHQL /graphql] at line 5"}, rr.Header().Values(MatchHeader))
+
+	rr = postGraphQL(ts, `{"query":"query GetUser { user }","variables":{"id":2}}`)
+	assert.Equal(t, []string{"none"}, rr.Header().Values(MatchHeader))
+	assert.Equal(t, []string{
+		"[GRAPHQL /graphql] at line 1: variable id differs",
+		"[GRAPHQL /graphql] at line 5: type mutation differs from query",
+	}, rr.Header().Values(NearMissHeader))
+
+	rr = postGraphQL(ts, `[{"query":"mutation { save }"},{"query":"{ other }"}]`)
+	assert.Equal(t, []string{"[GRAPHQL /graphql] at line 5", "none"}, rr.Header().Values(MatchHeader))
+	assert.Equal(t, []string{
+		"[GRAPHQL /graphql] at line 1: operation GetUser differs from anonymous query, variable id differs",
+		"[GRAPHQL /graphql] at line 5: type mutation differs from query",
+	}, rr.Header().Values(NearMissHeader))
+
+	ts.SetExplainMode(ExplainLog)
+	postGraphQL(ts, `{"query":"query Other { other }"}`)
+	assert.Contains(t, out.String(), "Request POST /graphql operation Other matched no behavior, near misses: "+
+		"[GRAPHQL /graphql] at line 1: operation GetUser differs from Other, variable id differs")
+}
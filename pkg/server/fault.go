@@ -0,0 +1,115 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+// pickFault rolls once and returns the fault whose share of requests was hit, if any.
+func (s *Server) pickFault(faults []*model.Fault) *model.Fault {
+	if len(faults) == 0 {
+		return nil
+	}
+
+	roll := s.randomFloat()
+	cumulative := 0.0
+	for _, fault := range faults {
+		cumulative += fault.Probability
+		if roll < cumulative {
+			return fault
+		}
+	}
+	return nil
+}
+
+// injectFault writes a faulty response for the given behavior.
+func injectFault(
+	ctx context.Context, w http.ResponseWriter, fault *model.Fault, statusCode int, behavior *model.ResponseBehavior,
+) {
+	if fault.Mode == model.FaultSlowBody {
+		writeSlowBody(ctx, w, fault.Value, statusCode, behavior)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "Hijacking unsupported", http.StatusInternalServerError)
+		return
+	}
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	defer conn.Close()
+
+	switch fault.Mode { //nolint:exhaustive // slow_body is written above without hijacking.
+	case model.FaultReset:
+		if tcpConn, isTCP := conn.(*net.TCPConn); isTCP {
+			// Discard unsent data on close so that the peer receives a RST.
+			_ = tcpConn.SetLinger(0)
+		}
+	case model.FaultEmpty:
+	case model.FaultTruncate:
+		writeTruncated(buf, fault.Value, statusCode, behavior)
+	case model.FaultMalformedHeaders:
+		fmt.Fprintf(buf, "HTTP/1.1 %d %s\r\n", statusCode, http.StatusText(statusCode))
+		fmt.Fprint(buf, "Content-Type text/plain\r\n: missing-name\r\nContent-Length: -1\r\n\r\n")
+		_ = buf.Flush()
+	}
+}
+
+// writeTruncated writes a response announcing the full body length but
+// sending only the first n bytes of it.
+func writeTruncated(buf *bufio.ReadWriter, n int, statusCode int, behavior *model.ResponseBehavior) {
+	body := ""
+	if behavior.Body != nil {
+		body = *behavior.Body
+	}
+	if n > len(body) {
+		n = len(body)
+	}
+
+	header := http.Header{}
+	for key, value := range behavior.Headers {
+		header.Set(key, value)
+	}
+	for _, cookie := range behavior.Cookies {
+		header.Add("Set-Cookie", cookie.String())
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	fmt.Fprintf(buf, "HTTP/1.1 %d %s\r\n", statusCode, http.StatusText(statusCode))
+	_ = header.Write(buf)
+	fmt.Fprint(buf, "\r\n", body[:n])
+	_ = buf.Flush()
+}
+
+// writeSlowBody writes the body trickling the given number of bytes per second.
+func writeSlowBody(
+	ctx context.Context, w http.ResponseWriter, bytesPerSecond int, statusCode int, behavior *model.ResponseBehavior,
+) {
+	for key, value := range behavior.Headers {
+		w.Header().Set(key, value)
+	}
+	for _, cookie := range behavior.Cookies {
+		http.SetCookie(w, cookie)
+	}
+	w.WriteHeader(statusCode)
+
+	if behavior.Body == nil {
+		return
+	}
+
+	bandwidth := uint64(bytesPerSecond) //nolint:gosec
+	throttled := *behavior
+	throttled.Bandwidth = &bandwidth
+	throttled.ChunkSize = nil
+	writeThrottled(ctx, w, []byte(*behavior.Body), &throttled)
+}
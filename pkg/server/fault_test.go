@@ -0,0 +1,103 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFaultServer(t *testing.T, body string, faults ...*model.Fault) *httptest.Server {
+	t.Helper()
+	beh := &model.Behavior{
+		Method:           http.MethodGet,
+		URL:              "/fault",
+		ResponseBehavior: &model.ResponseBehavior{Body: &body, Faults: faults},
+	}
+	ts := newTestServer([]*model.Behavior{beh}, nil)
+	srv := httptest.NewServer(http.HandlerFunc(ts.handleRequest))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFault_Reset(t *testing.T) {
+	srv := newFaultServer(t, "body", &model.Fault{Mode: model.FaultReset, Probability: 1})
+	_, err := http.Get(srv.URL + "/fault")
+	require.Error(t, err)
+}
+
+func TestFault_Empty(t *testing.T) {
+	srv := newFaultServer(t, "body", &model.Fault{Mode: model.FaultEmpty, Probability: 1})
+	_, err := http.Get(srv.URL + "/fault")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "EOF")
+}
+
+func TestFault_Truncate(t *testing.T) {
+	srv := newFaultServer(t, "hello world", &model.Fault{Mode: model.FaultTruncate, Value: 5, Probability: 1})
+	resp, err := http.Get(srv.URL + "/fault")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(11), resp.ContentLength)
+	body, err := io.ReadAll(resp.Body)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, "hello", string(body))
+}
+
+func TestFault_MalformedHeaders(t *testing.T) {
+	srv := newFaultServer(t, "body", &model.Fault{Mode: model.FaultMalformedHeaders, Probability: 1})
+	_, err := http.Get(srv.URL + "/fault")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "malformed")
+}
+
+func TestFault_SlowBody(t *testing.T) {
+	srv := newFaultServer(t, "0123456789", &model.Fault{Mode: model.FaultSlowBody, Value: 20, Probability: 1})
+	start := time.Now()
+	resp, err := http.Get(srv.URL + "/fault")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", string(body))
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+}
+
+func TestFault_Unsupported(t *testing.T) {
+	body := "body"
+	beh := &model.Behavior{
+		Method: http.MethodGet,
+		URL:    "/fault",
+		ResponseBehavior: &model.ResponseBehavior{
+			Body:   &body,
+			Faults: []*model.Fault{{Mode: model.FaultReset, Probability: 1}},
+		},
+	}
+	ts := newTestServer([]*model.Behavior{beh}, nil)
+	r := httptest.NewRequest(http.MethodGet, "/fault", nil)
+	w := httptest.NewRecorder()
+	ts.handleRequest(w, r)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestPickFault(t *testing.T) {
+	ts := newTestServer(nil, nil)
+	assert.Nil(t, ts.pickFault(nil))
+	reset := &model.Fault{Mode: model.FaultReset, Probability: 1}
+	assert.Equal(t, reset, ts.pickFault([]*model.Fault{reset}))
+
+	empty := &model.Fault{Mode: model.FaultEmpty, Probability: 0.5}
+	hits := 0
+	for range 1000 {
+		if ts.pickFault([]*model.Fault{empty}) != nil {
+			hits++
+		}
+	}
+	assert.InDelta(t, 500, hits, 100)
+}
@@ -0,0 +1,363 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+// graphQLOperation is a single operation of a GraphQL request.
+type graphQLOperation struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+
+	name          string
+	operationType model.GraphQLOperationType
+}
+
+// graphQLResult is the result of a GraphQL operation, payloads are raw JSON.
+type graphQLResult struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors json.RawMessage `json:"errors,omitempty"`
+}
+
+// servesGraphQL reports whether the request is answered by the GRAPHQL behaviors of its path.
+// If other behaviors are declared for the method and path, only GraphQL requests are,
+// so that e.g. a `[GET /graphql]` playground is still served.
+func (s *Server) servesGraphQL(r *http.Request) bool {
+	s.mutex.Lock()
+	declared := slices.ContainsFunc(s.behaviorSet.Behaviors, func(behavior *model.Behavior) bool {
+		return behavior.Method == model.MethodGraphQL && pathMatches(behavior.URL, r.URL.Path)
+	})
+	shared := slices.ContainsFunc(s.behaviorSet.Behaviors, func(behavior *model.Behavior) bool {
+		return behavior.Method != model.MethodGraphQL && methodMatches(behavior.Method, r) &&
+			pathMatches(behavior.URL, r.URL.Path)
+	})
+	s.mutex.Unlock()
+
+	if !declared || !shared {
+		return declared
+	}
+	_, _, err := parseGraphQLRequest(r)
+	return err == nil
+}
+
+// serveGraphQL matches every operation of a GraphQL request against the GRAPHQL behaviors of the path.
+// A single operation is answered like any other behavior and falls back to the default behavior
+// if none matches. Batched operations are answered together in one JSON array with status 200,
+// using the longest delay and the merged headers and cookies of the matched behaviors.
+// Their status codes, faults and streams are not applied and unmatched operations get an error result.
+func (s *Server) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	operations, batched, err := parseGraphQLRequest(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, graphQLError(err.Error()))
+		return
+	}
+
+	behaviors := make([]*model.ResponseBehavior, len(operations))
+	var explanations []*matchExplanation
+	s.mutex.Lock()
+	defaultBehavior := s.behaviorSet.DefaultBehavior
+	for i, operation := range operations {
+		var matched *model.Behavior
+		behaviors[i] = s.takeBehavior(func(behavior *model.Behavior) bool {
+			if behavior.Method == model.MethodGraphQL && pathMatches(behavior.URL, r.URL.Path) &&
+				graphQLMatches(behavior.GraphQLMatch, operation) {
+				matched = behavior
+				return true
+			}
+			return false
+		})
+		explanation := s.explanation(matched, !batched && defaultBehavior != nil, func() []nearMiss {
+			return s.graphQLNearMisses(r.URL.Path, operation)
+		})
+		if explanation != nil {
+			explanation.operation = graphQLOperationName(operation)
+			explanations = append(explanations, explanation)
+		}
+	}
+	s.mutex.Unlock()
+
+	for _, explanation := range explanations {
+		explain(w, r, explanation)
+	}
+
+	if !batched {
+		switch {
+		case behaviors[0] != nil:
+			s.respond(w, r, behaviors[0], http.StatusOK)
+		case defaultBehavior != nil:
+			s.respond(w, r, defaultBehavior, http.StatusNotFound)
+		default:
+			writeJSON(w, http.StatusNotFound, graphQLUnmatched(operations[0]))
+		}
+		return
+	}
+
+	results := make([]any, len(operations))
+	delay := time.Duration(0)
+	for i, behavior := range behaviors {
+		if behavior == nil {
+			results[i] = graphQLUnmatched(operations[i])
+			continue
+		}
+		behavior = s.pickVariant(behavior)
+		delay = max(delay, s.sampleDelay(behavior))
+		for key, value := range behavior.Headers {
+			w.Header().Set(key, value)
+		}
+		for _, cookie := range behavior.Cookies {
+			http.SetCookie(w, cookie)
+		}
+		results[i] = json.RawMessage(graphQLBody(behavior))
+	}
+
+	if !sleep(r.Context(), delay) {
+		return
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// withGraphQLBody returns a copy of the behavior that responds with its GraphQL result as JSON body.
+func withGraphQLBody(behavior *model.ResponseBehavior) *model.ResponseBehavior {
+	resolved := *behavior
+	body := graphQLBody(behavior)
+	resolved.Body = &body
+	resolved.Headers = maps.Clone(behavior.Headers)
+	if resolved.Headers == nil {
+		resolved.Headers = make(map[string]string)
+	}
+	if _, ok := resolved.Headers["Content-Type"]; !ok {
+		resolved.Headers["Content-Type"] = "application/json"
+	}
+	return &resolved
+}
+
+// graphQLBody builds the result of a behavior from its data and errors.
+// Behaviors without GraphQL payloads respond with their body, which must be JSON in batches.
+func graphQLBody(behavior *model.ResponseBehavior) string {
+	if behavior.GraphQL == nil {
+		if behavior.Body != nil && json.Valid([]byte(*behavior.Body)) {
+			return *behavior.Body
+		}
+		return `{"data":null}`
+	}
+
+	result := graphQLResult{Data: json.RawMessage("null")}
+	if behavior.GraphQL.Data != nil {
+		result.Data = json.RawMessage(*behavior.GraphQL.Data)
+	} else if behavior.GraphQL.Errors != nil {
+		result.Data = nil
+	}
+	if behavior.GraphQL.Errors != nil {
+		result.Errors = json.RawMessage(*behavior.GraphQL.Errors)
+	}
+	raw, _ := json.Marshal(result)
+	return string(raw)
+}
+
+// graphQLError returns a result with a single error message.
+func graphQLError(message string) graphQLResult {
+	raw, _ := json.Marshal([]map[string]string{{"message": message}})
+	return graphQLResult{Errors: raw}
+}
+
+// graphQLUnmatched returns the error result for an operation without matching behavior.
+func graphQLUnmatched(operation *graphQLOperation) graphQLResult {
+	return graphQLError("No behavior matches operation " + graphQLOperationName(operation))
+}
+
+// graphQLOperationName returns the name of an operation, like `GetUser` or `anonymous query`.
+func graphQLOperationName(operation *graphQLOperation) string {
+	if operation.name == "" {
+		return "anonymous " + string(operation.operationType)
+	}
+	return operation.name
+}
+
+// graphQLMatches reports whether the operation satisfies all criteria of the match.
+func graphQLMatches(match *model.GraphQLMatch, operation *graphQLOperation) bool {
+	if match == nil {
+		return true
+	}
+	if match.OperationName != nil && *match.OperationName != operation.name {
+		return false
+	}
+	if match.OperationType != nil && *match.OperationType != operation.operationType {
+		return false
+	}
+	for name, expected := range match.Variables {
+		actual, ok := operation.Variables[name]
+		if !ok || !reflect.DeepEqual(expected, actual) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseGraphQLRequest reads the operations of a GraphQL request.
+// GET requests carry the operation in query parameters, POST requests as JSON object,
+// JSON array of objects (batch) or as plain query with the application/graphql content type.
+//
+//nolint:cyclop
+func parseGraphQLRequest(r *http.Request) ([]*graphQLOperation, bool, error) {
+	var operations []*graphQLOperation
+	batched := false
+	params := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodGet:
+		operation := &graphQLOperation{Query: params.Get("query"), OperationName: params.Get("operationName")}
+		if variables := params.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &operation.Variables); err != nil {
+				return nil, false, fmt.Errorf("invalid variables: %w", err)
+			}
+		}
+		operations = append(operations, operation)
+	case r.Method != http.MethodPost:
+		return nil, false, errors.New("GraphQL requests must use GET or POST")
+	default:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read request: %w", err)
+		}
+		// Restore the body for a proxy.
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/graphql") {
+			operations = append(operations, &graphQLOperation{Query: string(body), OperationName: params.Get("operationName")})
+			break
+		}
+
+		trimmed := strings.TrimSpace(string(body))
+		if batched = strings.HasPrefix(trimmed, "["); batched {
+			err = json.Unmarshal(body, &operations)
+		} else {
+			operation := &graphQLOperation{}
+			err = json.Unmarshal(body, operation)
+			operations = append(operations, operation)
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid request body: %w", err)
+		}
+		if len(operations) == 0 {
+			return nil, false, errors.New("empty batch")
+		}
+	}
+
+	for _, operation := range operations {
+		if operation == nil || strings.TrimSpace(operation.Query) == "" {
+			return nil, false, errors.New("missing query")
+		}
+		operation.name, operation.operationType = describeGraphQLOperation(operation.Query, operation.OperationName)
+	}
+	return operations, batched, nil
+}
+
+// graphQLDefinition is an operation definition found in a GraphQL document.
+type graphQLDefinition struct {
+	name          string
+	operationType model.GraphQLOperationType
+}
+
+// describeGraphQLOperation returns the name and type of the operation that a request executes.
+// The operation is selected by operationName or, if it is empty, the first operation of the document is used.
+func describeGraphQLOperation(query, operationName string) (string, model.GraphQLOperationType) {
+	definitions := scanGraphQLDefinitions(query)
+	for _, definition := range definitions {
+		if operationName == "" || definition.name == operationName {
+			return definition.name, definition.operationType
+		}
+	}
+	return operationName, model.GraphQLQuery
+}
+
+// scanGraphQLDefinitions lists the operation definitions of a GraphQL document.
+// Only the top level of the document is inspected, selection sets, arguments,
+// strings and comments are skipped, and fragments are ignored.
+//
+//nolint:gocognit,cyclop
+func scanGraphQLDefinitions(query string) []graphQLDefinition {
+	var definitions []graphQLDefinition
+	var current *graphQLDefinition
+	depth := 0
+	fragment, expectName := false, false
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case c == '"':
+			if strings.HasPrefix(query[i:], `"""`) {
+				end := strings.Index(query[i+3:], `"""`)
+				if end < 0 {
+					return definitions
+				}
+				i += end + 5
+				break
+			}
+			for i++; i < len(query) && query[i] != '"'; i++ {
+				if query[i] == '\\' {
+					i++
+				}
+			}
+		case c == '{' || c == '(':
+			if depth == 0 && c == '{' {
+				switch {
+				case fragment:
+				case current != nil:
+					definitions = append(definitions, *current)
+				default:
+					definitions = append(definitions, graphQLDefinition{operationType: model.GraphQLQuery})
+				}
+				current, fragment = nil, false
+			}
+			expectName = false
+			depth++
+		case c == '}' || c == ')':
+			depth--
+		case depth == 0 && isGraphQLNameStart(c):
+			start := i
+			for i+1 < len(query) && isGraphQLNameChar(query[i+1]) {
+				i++
+			}
+			word := query[start : i+1]
+			switch {
+			case expectName:
+				current.name = word
+				expectName = false
+			case current == nil && !fragment && word == "fragment":
+				fragment = true
+			case current == nil && !fragment:
+				if operationType, ok := model.GraphQLOperationTypeFromString(word); ok && string(operationType) == word {
+					current = &graphQLDefinition{operationType: operationType}
+					expectName = true
+				}
+			}
+		case depth == 0 && c == '@':
+			expectName = false
+		}
+	}
+	return definitions
+}
+
+func isGraphQLNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isGraphQLNameChar(c byte) bool {
+	return isGraphQLNameStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func graphQLBehavior(match *model.GraphQLMatch, response *model.GraphQLResponse) *model.Behavior {
+	return &model.Behavior{
+		Method:           model.MethodGraphQL,
+		URL:              "/graphql",
+		GraphQLMatch:     match,
+		ResponseBehavior: &model.ResponseBehavior{GraphQL: response},
+	}
+}
+
+func postGraphQL(ts *mockServer, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	ts.handleRequest(rr, req)
+	return rr
+}
+
+func TestServeGraphQL_MatchesOperation(t *testing.T) {
+	mutation := model.GraphQLMutation
+	ts := newTestServer([]*model.Behavior{
+		graphQLBehavior(
+			&model.GraphQLMatch{OperationName: strPtr("GetUser"), Variables: map[string]any{"id": float64(1)}},
+			&model.GraphQLResponse{Data: strPtr(`{"user":{"name":"Ada"}}`)},
+		),
+		graphQLBehavior(
+			&model.GraphQLMatch{OperationName: strPtr("GetUser")},
+			&model.GraphQLResponse{Errors: strPtr(`[{"message":"not found"}]`)},
+		),
+		graphQLBehavior(
+			&model.GraphQLMatch{OperationType: &mutation},
+			&model.GraphQLResponse{Data: strPtr(`{"ok":true}`)},
+		),
+	}, nil)
+
+	rr := postGraphQL(ts, `{"query":"query GetUser($id: ID!) { user(id: $id) { name } }","variables":{"id":1}}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"data":{"user":{"name":"Ada"}}}`, rr.Body.String())
+
+	rr = postGraphQL(ts, `{"query":"query GetUser($id: ID!) { user(id: $id) { name } }","variables":{"id":2}}`)
+	assert.JSONEq(t, `{"errors":[{"message":"not found"}]}`, rr.Body.String())
+
+	rr = postGraphQL(ts, `{"query":"mutation { save }"}`)
+	assert.JSONEq(t, `{"data":{"ok":true}}`, rr.Body.String())
+
+	rr = postGraphQL(ts, `{"query":"{ other }"}`)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.JSONEq(t, `{"errors":[{"message":"No behavior matches operation anonymous query"}]}`, rr.Body.String())
+}
+
+func TestServeGraphQL_DefaultBehavior(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte("upstream " + string(body)))
+	}))
+	defer upstream.Close()
+
+	behaviors := []*model.Behavior{graphQLBehavior(
+		&model.GraphQLMatch{OperationName: strPtr("GetUser")},
+		&model.GraphQLResponse{Data: strPtr(`{"user":null}`)},
+	)}
+	request := `{"query":"query Other { other }"}`
+
+	ts := newTestServer(behaviors, &model.ResponseBehavior{Body: strPtr("fallback")})
+	rr := postGraphQL(ts, request)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "fallback", rr.Body.String())
+
+	ts = newTestServer(behaviors, &model.ResponseBehavior{Proxy: &upstream.URL})
+	rr = postGraphQL(ts, request)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "upstream "+request, rr.Body.String())
+}
+
+func TestServeGraphQL_Batch(t *testing.T) {
+	ts := newTestServer([]*model.Behavior{
+		graphQLBehavior(
+			&model.GraphQLMatch{OperationName: strPtr("A")},
+			&model.GraphQLResponse{Data: strPtr(`{"a":1}`)},
+		),
+		graphQLBehavior(
+			&model.GraphQLMatch{OperationName: strPtr("B")},
+			&model.GraphQLResponse{Data: strPtr(`{"b":2}`), Errors: strPtr(`[{"message":"partial"}]`)},
+		),
+	}, nil)
+
+	rr := postGraphQL(ts, `[{"query":"query A { a }"},{"query":"query B { b }"},{"query":"query C { c }"}]`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[
+		{"data":{"a":1}},
+		{"data":{"b":2},"errors":[{"message":"partial"}]},
+		{"errors":[{"message":"No behavior matches operation C"}]}
+	]`, rr.Body.String())
+}
+
+func TestServeGraphQL_GetAndPlainQuery(t *testing.T) {
+	ts := newTestServer([]*model.Behavior{
+		graphQLBehavior(
+			&model.GraphQLMatch{OperationName: strPtr("Second"), Variables: map[string]any{"tag": "go"}},
+			&model.GraphQLResponse{Data: strPtr(`{"second":true}`)},
+		),
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet,
+		`/graphql?query=query+First+%7B+a+%7D+query+Second+%7B+b+%7D&operationName=Second&variables=%7B%22tag%22%3A%22go%22%7D`, nil)
+	rr := httptest.NewRecorder()
+	ts.handleRequest(rr, req)
+	assert.JSONEq(t, `{"data":{"second":true}}`, rr.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/graphql?operationName=Second", strings.NewReader("query First { a } query Second { b }"))
+	req.Header.Set("Content-Type", "application/graphql")
+	rr = httptest.NewRecorder()
+	ts.handleRequest(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestServeGraphQL_InvalidRequest(t *testing.T) {
+	ts := newTestServer([]*model.Behavior{graphQLBehavior(nil, nil)}, nil)
+
+	for _, body := range []string{`{`, `[]`, `{"query":""}`} {
+		rr := postGraphQL(ts, body)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+	}
+}
+
+func TestServeGraphQL_SharedPath(t *testing.T) {
+	ts := newTestServer([]*model.Behavior{
+		graphQLBehavior(nil, &model.GraphQLResponse{Data: strPtr(`{"ok":true}`)}),
+		{Method: http.MethodGet, URL: "/graphql", ResponseBehavior: &model.ResponseBehavior{Body: strPtr("playground")}},
+		{Method: http.MethodPost, URL: "/graphql", ResponseBehavior: &model.ResponseBehavior{Body: strPtr("upload")}},
+	}, nil)
+
+	rr := httptest.NewRecorder()
+	ts.handleRequest(rr, httptest.NewRequest(http.MethodGet, "/graphql", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "playground", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	ts.handleRequest(rr, httptest.NewRequest(http.MethodGet, "/graphql?query=%7B+ok+%7D", nil))
+	assert.JSONEq(t, `{"data":{"ok":true}}`, rr.Body.String())
+
+	rr = postGraphQL(ts, `not graphql`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "upload", rr.Body.String())
+
+	rr = postGraphQL(ts, `{"query":"{ ok }"}`)
+	assert.JSONEq(t, `{"data":{"ok":true}}`, rr.Body.String())
+}
+
+func TestServeGraphQL_PlainBody(t *testing.T) {
+	beh := graphQLBehavior(nil, nil)
+	beh.Body = strPtr(`{"data":{"raw":true}}`)
+	status := uint16(http.StatusAccepted)
+	beh.StatusCode = &status
+	ts := newTestServer([]*model.Behavior{beh}, nil)
+
+	rr := postGraphQL(ts, `{"query":"{ raw }"}`)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	assert.JSONEq(t, `{"data":{"raw":true}}`, rr.Body.String())
+}
+
+func TestDescribeGraphQLOperation(t *testing.T) {
+	for _, tc := range []struct {
+		query         string
+		operationName string
+		name          string
+		operationType model.GraphQLOperationType
+	}{
+		{"{ user { name } }", "", "", model.GraphQLQuery},
+		{"query GetUser($id: ID!) { user(id: $id) { name } }", "", "GetUser", model.GraphQLQuery},
+		{"# mutation Fake\nmutation Save @live { save(note: \"query X {\") }", "", "Save", model.GraphQLMutation},
+		{"fragment F on User { id } subscription OnEvent { event { ...F } }", "", "OnEvent", model.GraphQLSubscription},
+		{"query A { a } mutation B { b }", "B", "B", model.GraphQLMutation},
+		{`query A { a(text: """block "quoted" {""") }`, "", "A", model.GraphQLQuery},
+	} {
+		name, operationType := describeGraphQLOperation(tc.query, tc.operationName)
+		assert.Equal(t, tc.name, name, tc.query)
+		assert.Equal(t, tc.operationType, operationType, tc.query)
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/descriptor"
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	grpcCodeInternal      = 13
+	grpcCodeUnimplemented = 12
+	grpcFrameHeaderSize   = 5
+)
+
+// SetDescriptors sets the protobuf descriptors used to encode gRPC responses.
+func (s *Server) SetDescriptors(files *protoregistry.Files) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.descriptors = files
+}
+
+// isGRPCRequest reports whether the request is a gRPC call.
+func isGRPCRequest(r *http.Request) bool {
+	return r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// serveGRPC responds to a gRPC call with the messages, status and trailers of the behavior.
+// Messages are declared as JSON and converted using the output type of the called method.
+//
+//nolint:cyclop
+func (s *Server) serveGRPC(w http.ResponseWriter, r *http.Request, behavior *model.ResponseBehavior) {
+	_, _ = io.Copy(io.Discard, r.Body)
+
+	for key, value := range behavior.Headers {
+		w.Header().Set(key, value)
+	}
+	w.Header().Set("Content-Type", "application/grpc+proto")
+	w.WriteHeader(http.StatusOK)
+
+	code, message := behavior.GRPC.Code, behavior.GRPC.Message
+	messages, errCode, err := s.encodeGRPCMessages(r.URL.Path, behavior)
+	if err != nil {
+		code, message = errCode, err.Error()
+	}
+
+	flusher, _ := w.(http.Flusher)
+	for i, msg := range messages {
+		pause := time.Duration(0)
+		if msg.delay != nil {
+			pause = *msg.delay
+		} else if i > 0 && behavior.ChunkDelay != nil {
+			pause = *behavior.ChunkDelay
+		}
+		if !sleep(r.Context(), pause) {
+			return
+		}
+
+		frame := make([]byte, grpcFrameHeaderSize, grpcFrameHeaderSize+len(msg.payload))
+		binary.BigEndian.PutUint32(frame[1:], uint32(len(msg.payload))) //nolint:gosec
+		if _, err = w.Write(append(frame, msg.payload...)); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	for key, value := range behavior.GRPC.Trailers {
+		w.Header().Set(http.TrailerPrefix+key, value)
+	}
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.FormatUint(uint64(code), 10))
+	if message != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", url.PathEscape(message))
+	}
+}
+
+// grpcMessage is an encoded response message with an optional delay before sending it.
+type grpcMessage struct {
+	payload []byte
+	delay   *time.Duration
+}
+
+// encodeGRPCMessages converts the JSON messages of the behavior into protobuf.
+// Chunks are used as server-streaming messages, otherwise the body is the single response message.
+// On error it returns the gRPC status code to respond with.
+func (s *Server) encodeGRPCMessages(path string, behavior *model.ResponseBehavior) ([]grpcMessage, uint32, error) {
+	var chunks []*model.Chunk
+	if behavior.Stream != nil && len(behavior.Stream.Chunks) > 0 {
+		chunks = behavior.Stream.Chunks
+	} else if behavior.Body != nil {
+		chunks = []*model.Chunk{{Data: *behavior.Body}}
+	}
+	if len(chunks) == 0 {
+		return nil, 0, nil
+	}
+
+	s.mutex.Lock()
+	files := s.descriptors
+	s.mutex.Unlock()
+	if files == nil {
+		return nil, grpcCodeUnimplemented, ErrNoDescriptors
+	}
+
+	method, err := descriptor.FindMethod(files, path)
+	if err != nil {
+		return nil, grpcCodeUnimplemented, err
+	}
+
+	messages := make([]grpcMessage, 0, len(chunks))
+	for _, chunk := range chunks {
+		msg := dynamicpb.NewMessage(method.Output())
+		if err = protojson.Unmarshal([]byte(chunk.Data), msg); err != nil {
+			return nil, grpcCodeInternal, err
+		}
+		payload, marshalErr := proto.Marshal(msg)
+		if marshalErr != nil {
+			return nil, grpcCodeInternal, marshalErr
+		}
+		messages = append(messages, grpcMessage{payload: payload, delay: chunk.Delay})
+	}
+	return messages, 0, nil
+}
@@ -0,0 +1,222 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func greeterDescriptors(t *testing.T) *protoregistry.Files {
+	t.Helper()
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("greeter.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("HelloReply"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("message"),
+				JsonName: proto.String("message"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			}},
+		}},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Greeter"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("SayHello"),
+				InputType:  proto.String(".test.HelloReply"),
+				OutputType: proto.String(".test.HelloReply"),
+			}},
+		}},
+	}}})
+	require.NoError(t, err)
+	return files
+}
+
+func newGRPCRequest() *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/test.Greeter/SayHello", strings.NewReader("\x00\x00\x00\x00\x00"))
+	req.Header.Set("Content-Type", "application/grpc")
+	return req
+}
+
+// decodeGRPCFrames returns the message field of every HelloReply frame in a gRPC response body.
+func decodeGRPCFrames(t *testing.T, files *protoregistry.Files, body []byte) []string {
+	t.Helper()
+	desc, err := files.FindDescriptorByName("test.HelloReply")
+	require.NoError(t, err)
+	messageDesc := desc.(protoreflect.MessageDescriptor)
+
+	var result []string
+	for len(body) > 0 {
+		require.GreaterOrEqual(t, len(body), grpcFrameHeaderSize)
+		size := int(binary.BigEndian.Uint32(body[1:grpcFrameHeaderSize]))
+		require.GreaterOrEqual(t, len(body), grpcFrameHeaderSize+size)
+
+		msg := dynamicpb.NewMessage(messageDesc)
+		require.NoError(t, proto.Unmarshal(body[grpcFrameHeaderSize:grpcFrameHeaderSize+size], msg))
+		result = append(result, msg.Get(messageDesc.Fields().ByName("message")).String())
+		body = body[grpcFrameHeaderSize+size:]
+	}
+	return result
+}
+
+func TestServeGRPC_Unary(t *testing.T) {
+	files := greeterDescriptors(t)
+	beh := &model.Behavior{
+		Method: model.MethodGRPC,
+		URL:    "/test.Greeter/SayHello",
+		ResponseBehavior: &model.ResponseBehavior{
+			Body: strPtr(`{"message":"hello"}`),
+			GRPC: &model.GRPCResponse{Trailers: map[string]string{"X-Request-Id": "42"}},
+		},
+	}
+	ts := newTestServer([]*model.Behavior{beh}, nil)
+	ts.SetDescriptors(files)
+
+	rr := httptest.NewRecorder()
+	ts.handleRequest(rr, newGRPCRequest())
+	res := rr.Result()
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/grpc+proto", res.Header.Get("Content-Type"))
+	assert.Equal(t, []string{"hello"}, decodeGRPCFrames(t, files, body))
+	assert.Equal(t, "0", res.Trailer.Get("Grpc-Status"))
+	assert.Equal(t, "42", res.Trailer.Get("X-Request-Id"))
+}
+
+func TestServeGRPC_Status(t *testing.T) {
+	beh := &model.Behavior{
+		Method: model.MethodGRPC,
+		URL:    "/test.Greeter/SayHello",
+		ResponseBehavior: &model.ResponseBehavior{
+			GRPC: &model.GRPCResponse{Code: 5, Message: "user not found"},
+		},
+	}
+	ts := newTestServer([]*model.Behavior{beh}, nil)
+
+	rr := httptest.NewRecorder()
+	ts.handleRequest(rr, newGRPCRequest())
+	res := rr.Result()
+	defer res.Body.Close()
+
+	assert.Empty(t, rr.Body.Bytes())
+	assert.Equal(t, "5", res.Trailer.Get("Grpc-Status"))
+	assert.Equal(t, "user%20not%20found", res.Trailer.Get("Grpc-Message"))
+}
+
+func TestServeGRPC_MissingDescriptors(t *testing.T) {
+	beh := &model.Behavior{
+		Method: model.MethodGRPC,
+		URL:    "/test.Greeter/SayHello",
+		ResponseBehavior: &model.ResponseBehavior{
+			Body: strPtr(`{"message":"hello"}`),
+			GRPC: &model.GRPCResponse{},
+		},
+	}
+	ts := newTestServer([]*model.Behavior{beh}, nil)
+
+	rr := httptest.NewRecorder()
+	ts.handleRequest(rr, newGRPCRequest())
+	res := rr.Result()
+	defer res.Body.Close()
+
+	assert.Empty(t, rr.Body.Bytes())
+	assert.Equal(t, "12", res.Trailer.Get("Grpc-Status"))
+}
+
+func TestServeGRPC_InvalidMessage(t *testing.T) {
+	beh := &model.Behavior{
+		Method: model.MethodGRPC,
+		URL:    "/test.Greeter/SayHello",
+		ResponseBehavior: &model.ResponseBehavior{
+			Body: strPtr(`{"unknown":"field"}`),
+			GRPC: &model.GRPCResponse{},
+		},
+	}
+	ts := newTestServer([]*model.Behavior{beh}, nil)
+	ts.SetDescriptors(greeterDescriptors(t))
+
+	rr := httptest.NewRecorder()
+	ts.handleRequest(rr, newGRPCRequest())
+	res := rr.Result()
+	defer res.Body.Close()
+
+	assert.Equal(t, "13", res.Trailer.Get("Grpc-Status"))
+}
+
+func TestServeGRPC_ServerStreamingOverH2C(t *testing.T) {
+	files := greeterDescriptors(t)
+	beh := &model.Behavior{
+		Method: model.MethodGRPC,
+		URL:    "/test.Greeter/SayHello",
+		ResponseBehavior: &model.ResponseBehavior{
+			Stream: &model.Stream{Chunks: []*model.Chunk{
+				{Data: `{"message":"one"}`},
+				{Data: `{"message":"two"}`},
+			}},
+			GRPC: &model.GRPCResponse{},
+		},
+	}
+	ts := newTestServer([]*model.Behavior{beh}, nil)
+	ts.SetDescriptors(files)
+	srv := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(ts.handleRequest), &http2.Server{}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost,
+		srv.URL+"/test.Greeter/SayHello", strings.NewReader("\x00\x00\x00\x00\x00"))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/grpc")
+
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, res.ProtoMajor)
+	assert.Equal(t, []string{"one", "two"}, decodeGRPCFrames(t, files, body))
+	assert.Equal(t, "0", res.Trailer.Get("Grpc-Status"))
+}
+
+func TestServeGRPC_IgnoresPlainRequests(t *testing.T) {
+	beh := &model.Behavior{
+		Method:           model.MethodGRPC,
+		URL:              "/test.Greeter/SayHello",
+		ResponseBehavior: &model.ResponseBehavior{GRPC: &model.GRPCResponse{}},
+	}
+	ts := newTestServer([]*model.Behavior{beh}, nil)
+
+	rr := httptest.NewRecorder()
+	ts.handleRequest(rr, httptest.NewRequest(http.MethodPost, "/test.Greeter/SayHello", nil))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
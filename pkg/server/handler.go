@@ -1,41 +1,86 @@
 package server
 
 import (
-	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/StevenCyb/ServMock/pkg/model"
 )
 
-//nolint:gocognit
 func (s *Server) handleRequest(w http.ResponseWriter, r *http.Request) {
-	matchingBehavior, statusCode := s.findMatchingBehavior(r)
+	if strings.HasPrefix(r.URL.Path, adminPathPrefix) {
+		s.handleAdmin(w, r)
+		return
+	}
+
+	if !s.validateRequest(w, r) {
+		return
+	}
+
+	if s.servesGraphQL(r) {
+		s.serveGraphQL(w, r)
+		return
+	}
+
+	matchingBehavior, statusCode, explanation := s.findMatchingBehavior(r)
+	if explanation != nil {
+		explain(w, r, explanation)
+	}
 	if matchingBehavior == nil {
 		http.Error(w, "Not Found", http.StatusNotFound)
 		return
 	}
+	s.respond(w, r, matchingBehavior, statusCode)
+}
+
+// respond writes the response of the matched behavior, statusCode is used unless the behavior sets one.
+//
+//nolint:gocognit
+func (s *Server) respond(w http.ResponseWriter, r *http.Request, matchingBehavior *model.ResponseBehavior, statusCode int) {
+	matchingBehavior = s.pickVariant(matchingBehavior)
+	if matchingBehavior.GraphQL != nil {
+		matchingBehavior = withGraphQLBody(matchingBehavior)
+	}
+
+	// Abort the delay if the client goes away or the server shuts down.
+	delay := s.sampleDelay(matchingBehavior)
+	if delay > 0 {
+		clearWriteDeadline(w)
+	}
+	if !sleep(r.Context(), delay) {
+		return
+	}
+
+	if matchingBehavior.GRPC != nil {
+		s.serveGRPC(w, r, matchingBehavior)
+		return
+	}
 
-	if matchingBehavior.Delay != nil {
-		time.Sleep(*matchingBehavior.Delay)
+	if matchingBehavior.WebSocket != nil {
+		serveWebSocket(w, r, matchingBehavior)
+		return
+	}
+
+	if matchingBehavior.Proxy != nil {
+		proxyRequest(w, r, matchingBehavior)
+		return
 	}
 
 	if matchingBehavior.StatusCode != nil {
 		statusCode = int(*matchingBehavior.StatusCode)
 	}
-	w.WriteHeader(statusCode)
 
-	if matchingBehavior.Headers != nil {
-		for key, value := range matchingBehavior.Headers {
-			w.Header().Set(key, value)
-		}
+	if fault := s.pickFault(matchingBehavior.Faults); fault != nil {
+		injectFault(r.Context(), w, fault, statusCode, matchingBehavior)
+		return
 	}
 
-	if matchingBehavior.Cookies != nil {
-		for _, cookie := range matchingBehavior.Cookies {
-			http.SetCookie(w, cookie)
-		}
+	for key, value := range matchingBehavior.Headers {
+		w.Header().Set(key, value)
+	}
+
+	for _, cookie := range matchingBehavior.Cookies {
+		http.SetCookie(w, cookie)
 	}
 
 	if matchingBehavior.Redirect != nil {
@@ -43,39 +88,54 @@ func (s *Server) handleRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if matchingBehavior.SSE {
-		w.Header().Set("Content-Type", "text/event-stream")
-		w.Header().Set("Cache-Control", "no-cache")
-		w.Header().Set("Connection", "keep-alive")
+	if matchingBehavior.SSE || matchingBehavior.Stream != nil {
+		writeStream(w, r, statusCode, matchingBehavior)
+		return
+	}
 
-		flusher, ok := w.(http.Flusher)
-		if !ok {
-			http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
-			return
-		}
+	w.WriteHeader(statusCode)
+	if matchingBehavior.Body != nil {
+		writeThrottled(r.Context(), w, []byte(*matchingBehavior.Body), matchingBehavior)
+	}
+}
 
-		if matchingBehavior.Body != nil {
-			for _, chunk := range strings.Split(*matchingBehavior.Body, "\n") {
-				fmt.Fprintf(w, "data: %s\n\n", chunk)
-				flusher.Flush()
-			}
+// findMatchingBehavior returns the response of the first behavior matching the request or the default.
+// If match decisions are explained, it also returns the explanation of the decision.
+func (s *Server) findMatchingBehavior(r *http.Request) (*model.ResponseBehavior, int, *matchExplanation) {
+	var statusCode = http.StatusOK
+	body := s.matchingBody(r)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var matched *model.Behavior
+	matchingBehavior := s.takeBehavior(func(behavior *model.Behavior) bool {
+		if methodMatches(behavior.Method, r) && pathMatches(behavior.URL, r.URL.Path) &&
+			requestMatches(behavior, r, body) {
+			matched = behavior
+			return true
 		}
+		return false
+	})
 
-		flusher.Flush()
-	} else if matchingBehavior.Body != nil {
-		if _, err := w.Write([]byte(*matchingBehavior.Body)); err != nil {
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
+	explanation := s.explanation(matched, s.behaviorSet.DefaultBehavior != nil, func() []nearMiss {
+		return s.nearMisses(r, body)
+	})
+
+	if matchingBehavior == nil {
+		statusCode = http.StatusNotFound
+		if s.behaviorSet.DefaultBehavior != nil {
+			matchingBehavior = s.behaviorSet.DefaultBehavior
 		}
 	}
+	return matchingBehavior, statusCode, explanation
 }
 
-func (s *Server) findMatchingBehavior(r *http.Request) (*model.ResponseBehavior, int) {
-	var matchingBehavior *model.ResponseBehavior
-	var statusCode = http.StatusOK
-
+// takeBehavior returns the response of the first behavior accepted by matches,
+// counting down its repeats and advancing its sequence. The caller must hold s.mutex.
+func (s *Server) takeBehavior(matches func(*model.Behavior) bool) *model.ResponseBehavior {
 	for i, behavior := range s.behaviorSet.Behaviors {
-		if behavior.Method == model.HTTPMethod(r.Method) && behavior.URL == r.URL.Path {
+		if matches(behavior) {
 			if behavior.Repeat != nil {
 				*behavior.Repeat--
 				if *behavior.Repeat <= 0 {
@@ -83,16 +143,8 @@ func (s *Server) findMatchingBehavior(r *http.Request) (*model.ResponseBehavior,
 				}
 			}
 
-			matchingBehavior = behavior.ResponseBehavior
-			break
-		}
-	}
-
-	if matchingBehavior == nil {
-		statusCode = http.StatusNotFound
-		if s.behaviorSet.DefaultBehavior != nil {
-			matchingBehavior = s.behaviorSet.DefaultBehavior
+			return nextStep(behavior)
 		}
 	}
-	return matchingBehavior, statusCode
+	return nil
 }
@@ -202,3 +202,105 @@ func TestHandleRequest_RepeatBehavior_Decrement(t *testing.T) {
 	assert.Equal(t, body, w2.Body.String())
 	assert.Empty(t, ts.behaviorSet.Behaviors, "Behavior should be removed after second call")
 }
+
+func TestHandleRequest_Proxy_Default(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Upstream", "true")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("upstream " + r.URL.Path + " " + r.Header.Get("X-Rewrite")))
+	}))
+	defer upstream.Close()
+
+	def := &model.ResponseBehavior{
+		Proxy:        &upstream.URL,
+		ProxyHeaders: map[string]string{"X-Rewrite": "yes"},
+		Headers:      map[string]string{"X-Mock": "val"},
+	}
+	ts := newTestServer([]*model.Behavior{}, def)
+	r := httptest.NewRequest(http.MethodGet, "/passthrough", nil)
+	w := httptest.NewRecorder()
+	ts.handleRequest(w, r)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "upstream /passthrough yes", w.Body.String())
+	assert.Equal(t, "true", w.Header().Get("X-Upstream"))
+	assert.Equal(t, "val", w.Header().Get("X-Mock"))
+}
+
+func TestHandleRequest_Proxy_Behavior(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("upstream " + r.URL.Path))
+	}))
+	defer upstream.Close()
+
+	body := "mocked"
+	mocked := &model.Behavior{
+		Method:           http.MethodGet,
+		URL:              "/mocked",
+		ResponseBehavior: &model.ResponseBehavior{Body: &body},
+	}
+	proxied := &model.Behavior{
+		Method:           http.MethodGet,
+		URL:              "/proxied",
+		ResponseBehavior: &model.ResponseBehavior{Proxy: &upstream.URL},
+	}
+	ts := newTestServer([]*model.Behavior{mocked, proxied}, nil)
+
+	r1 := httptest.NewRequest(http.MethodGet, "/mocked", nil)
+	w1 := httptest.NewRecorder()
+	ts.handleRequest(w1, r1)
+	assert.Equal(t, body, w1.Body.String())
+
+	r2 := httptest.NewRequest(http.MethodGet, "/proxied", nil)
+	w2 := httptest.NewRecorder()
+	ts.handleRequest(w2, r2)
+	assert.Equal(t, http.StatusOK, w2.Code)
+	assert.Equal(t, "upstream /proxied", w2.Body.String())
+}
+
+func TestHandleRequest_Proxy_Unreachable(t *testing.T) {
+	upstream := "http://127.0.0.1:1"
+	def := &model.ResponseBehavior{Proxy: &upstream}
+	ts := newTestServer([]*model.Behavior{}, def)
+	r := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+	w := httptest.NewRecorder()
+	ts.handleRequest(w, r)
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+}
+
+func TestHandleRequest_Variants(t *testing.T) {
+	ok := uint16(200)
+	fail := uint16(500)
+	beh := &model.Behavior{
+		Method: http.MethodGet,
+		URL:    "/variants",
+		ResponseBehavior: &model.ResponseBehavior{
+			Variants: []*model.Variant{
+				{Weight: 90, ResponseBehavior: &model.ResponseBehavior{StatusCode: &ok}},
+				{Weight: 10, ResponseBehavior: &model.ResponseBehavior{StatusCode: &fail}},
+			},
+		},
+	}
+
+	run := func(seed uint64) []int {
+		ts := newTestServer([]*model.Behavior{beh}, nil)
+		ts.SetSeed(seed)
+		codes := make([]int, 0, 1000)
+		for range 1000 {
+			r := httptest.NewRequest(http.MethodGet, "/variants", nil)
+			w := httptest.NewRecorder()
+			ts.handleRequest(w, r)
+			codes = append(codes, w.Code)
+		}
+		return codes
+	}
+
+	codes := run(42)
+	failures := 0
+	for _, code := range codes {
+		if code == http.StatusInternalServerError {
+			failures++
+		}
+	}
+	assert.InDelta(t, 100, failures, 40)
+	assert.Equal(t, codes, run(42), "Same seed should produce the same sequence")
+}
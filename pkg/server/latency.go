@@ -0,0 +1,61 @@
+package server
+
+import (
+	"math"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+const percent = 100
+
+// sampleDelay returns the delay to apply before responding.
+func (s *Server) sampleDelay(behavior *model.ResponseBehavior) time.Duration {
+	if behavior.Latency == nil {
+		if behavior.Delay != nil {
+			return *behavior.Delay
+		}
+		return 0
+	}
+
+	latency := behavior.Latency
+	var delay float64
+	switch latency.Distribution {
+	case model.LatencyUniform:
+		low, high := float64(latency.Params[0]), float64(latency.Params[1])
+		delay = low + s.randomFloat()*(high-low)
+	case model.LatencyNormal:
+		mean, stddev := float64(latency.Params[0]), float64(latency.Params[1])
+		delay = mean + s.randomNormFloat()*stddev
+	case model.LatencyLogNormal:
+		// Convert mean and stddev of the delay into the parameters of the underlying normal distribution.
+		mean, stddev := float64(latency.Params[0]), float64(latency.Params[1])
+		sigma2 := math.Log(1 + (stddev*stddev)/(mean*mean))
+		mu := math.Log(mean) - sigma2/2
+		delay = math.Exp(mu + s.randomNormFloat()*math.Sqrt(sigma2))
+	case model.LatencyPercentiles:
+		delay = samplePercentiles(latency.Percentiles, s.randomFloat()*percent)
+	}
+
+	return time.Duration(max(0, delay))
+}
+
+// samplePercentiles interpolates linearly between the given percentiles.
+// Below the first percentile it interpolates from zero, above the last it sticks to the last delay.
+func samplePercentiles(percentiles []model.Percentile, roll float64) float64 {
+	if len(percentiles) == 0 {
+		return 0
+	}
+
+	lowP, lowD := 0.0, 0.0
+	for _, p := range percentiles {
+		if roll <= p.Percentile {
+			if p.Percentile == lowP {
+				return float64(p.Delay)
+			}
+			return lowD + (roll-lowP)/(p.Percentile-lowP)*(float64(p.Delay)-lowD)
+		}
+		lowP, lowD = p.Percentile, float64(p.Delay)
+	}
+	return lowD
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleDelays(ts *mockServer, behavior *model.ResponseBehavior, n int) []time.Duration {
+	delays := make([]time.Duration, 0, n)
+	for range n {
+		delays = append(delays, ts.sampleDelay(behavior))
+	}
+	return delays
+}
+
+func TestSampleDelay_Fixed(t *testing.T) {
+	ts := newTestServer(nil, nil)
+	d := 10 * time.Millisecond
+	assert.Equal(t, d, ts.sampleDelay(&model.ResponseBehavior{Delay: &d}))
+	assert.Equal(t, time.Duration(0), ts.sampleDelay(&model.ResponseBehavior{}))
+}
+
+func TestSampleDelay_Uniform(t *testing.T) {
+	ts := newTestServer(nil, nil)
+	behavior := &model.ResponseBehavior{Latency: &model.Latency{
+		Distribution: model.LatencyUniform,
+		Params:       []time.Duration{100 * time.Millisecond, 500 * time.Millisecond},
+	}}
+	for _, delay := range sampleDelays(ts, behavior, 1000) {
+		assert.GreaterOrEqual(t, delay, 100*time.Millisecond)
+		assert.Less(t, delay, 500*time.Millisecond)
+	}
+}
+
+func TestSampleDelay_Normal(t *testing.T) {
+	ts := newTestServer(nil, nil)
+	ts.SetSeed(1)
+	behavior := &model.ResponseBehavior{Latency: &model.Latency{
+		Distribution: model.LatencyNormal,
+		Params:       []time.Duration{200 * time.Millisecond, 50 * time.Millisecond},
+	}}
+	total := time.Duration(0)
+	for _, delay := range sampleDelays(ts, behavior, 1000) {
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		total += delay
+	}
+	assert.InDelta(t, float64(200*time.Millisecond), float64(total/1000), float64(10*time.Millisecond))
+}
+
+func TestSampleDelay_LogNormal(t *testing.T) {
+	ts := newTestServer(nil, nil)
+	ts.SetSeed(1)
+	behavior := &model.ResponseBehavior{Latency: &model.Latency{
+		Distribution: model.LatencyLogNormal,
+		Params:       []time.Duration{200 * time.Millisecond, 100 * time.Millisecond},
+	}}
+	total := time.Duration(0)
+	for _, delay := range sampleDelays(ts, behavior, 5000) {
+		assert.Positive(t, delay)
+		total += delay
+	}
+	assert.InDelta(t, float64(200*time.Millisecond), float64(total/5000), float64(10*time.Millisecond))
+}
+
+func TestSampleDelay_Percentiles(t *testing.T) {
+	percentiles := []model.Percentile{
+		{Percentile: 50, Delay: 20 * time.Millisecond},
+		{Percentile: 99, Delay: 800 * time.Millisecond},
+	}
+	assert.InDelta(t, float64(10*time.Millisecond), samplePercentiles(percentiles, 25), 1)
+	assert.InDelta(t, float64(20*time.Millisecond), samplePercentiles(percentiles, 50), 1)
+	assert.InDelta(t, float64(800*time.Millisecond), samplePercentiles(percentiles, 99), 1)
+	assert.InDelta(t, float64(800*time.Millisecond), samplePercentiles(percentiles, 99.9), 1)
+	assert.Zero(t, samplePercentiles(nil, 50))
+
+	ts := newTestServer(nil, nil)
+	behavior := &model.ResponseBehavior{Latency: &model.Latency{
+		Distribution: model.LatencyPercentiles,
+		Percentiles:  percentiles,
+	}}
+	below := 0
+	for _, delay := range sampleDelays(ts, behavior, 1000) {
+		assert.LessOrEqual(t, delay, 800*time.Millisecond)
+		if delay <= 20*time.Millisecond {
+			below++
+		}
+	}
+	assert.InDelta(t, 500, below, 60)
+}
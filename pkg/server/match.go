@@ -0,0 +1,72 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+// requestMatches reports whether the request satisfies the query and body conditions of the behavior.
+// Body is the request body, which is only read if some behavior matches on it.
+func requestMatches(behavior *model.Behavior, r *http.Request, body *string) bool {
+	if behavior.Query != nil && !queryEquals(behavior.Query, r.URL.Query()) {
+		return false
+	}
+	if behavior.RequestBody != nil && (body == nil || !bodyEquals(*behavior.RequestBody, *body)) {
+		return false
+	}
+	return true
+}
+
+// queryEquals compares two queries, ignoring the order of parameters but not of repeated values.
+func queryEquals(expected, actual url.Values) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+	for key, values := range expected {
+		if !slices.Equal(values, actual[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// bodyEquals compares two bodies, JSON bodies are compared by their decoded value.
+func bodyEquals(expected, actual string) bool {
+	expected, actual = strings.TrimSpace(expected), strings.TrimSpace(actual)
+	if expected == actual {
+		return true
+	}
+	var expectedJSON, actualJSON any
+	if json.Unmarshal([]byte(expected), &expectedJSON) != nil || json.Unmarshal([]byte(actual), &actualJSON) != nil {
+		return false
+	}
+	return reflect.DeepEqual(expectedJSON, actualJSON)
+}
+
+// matchingBody reads and restores the request body if any behavior matches on bodies.
+func (s *Server) matchingBody(r *http.Request) *string {
+	s.mutex.Lock()
+	needed := slices.ContainsFunc(s.behaviorSet.Behaviors, func(behavior *model.Behavior) bool {
+		return behavior.RequestBody != nil
+	})
+	s.mutex.Unlock()
+	if !needed || r.Body == nil {
+		return nil
+	}
+
+	raw, err := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(raw))
+	if err != nil {
+		return nil
+	}
+	body := string(raw)
+	return &body
+}
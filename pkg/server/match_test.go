@@ -0,0 +1,63 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleRequest_MatchQueryAndBody(t *testing.T) {
+	first, second, fallback := "first", "second", "fallback"
+	ts := newTestServer([]*model.Behavior{
+		{
+			Method:           http.MethodPost,
+			URL:              "/search",
+			Query:            url.Values{"q": {"go"}, "page": {"1"}},
+			RequestBody:      strPtr(`{"filter": {"tag": "a"}}`),
+			ResponseBehavior: &model.ResponseBehavior{Body: &first},
+		},
+		{
+			Method:           http.MethodPost,
+			URL:              "/search",
+			Query:            url.Values{"q": {"go"}, "page": {"2"}},
+			ResponseBehavior: &model.ResponseBehavior{Body: &second},
+		},
+		{
+			Method:           http.MethodPost,
+			URL:              "/search",
+			ResponseBehavior: &model.ResponseBehavior{Body: &fallback},
+		},
+	}, nil)
+
+	for _, tc := range []struct {
+		target string
+		body   string
+		want   string
+	}{
+		{"/search?page=1&q=go", `{"filter":{"tag":"a"}}`, first},
+		{"/search?page=1&q=go", `{"filter":{"tag":"b"}}`, fallback},
+		{"/search?q=go&page=2", `ignored`, second},
+		{"/search?q=go&page=2&extra=1", ``, fallback},
+	} {
+		rr := httptest.NewRecorder()
+		ts.handleRequest(rr, httptest.NewRequest(http.MethodPost, tc.target, strings.NewReader(tc.body)))
+		assert.Equal(t, tc.want, rr.Body.String(), tc.target+" "+tc.body)
+	}
+}
+
+func TestMatchingBody_RestoresBody(t *testing.T) {
+	ts := newTestServer([]*model.Behavior{{RequestBody: strPtr("x"), ResponseBehavior: &model.ResponseBehavior{}}}, nil)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("payload"))
+
+	assert.Equal(t, "payload", *ts.matchingBody(req))
+	raw, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "payload", string(raw))
+}
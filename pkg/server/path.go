@@ -0,0 +1,37 @@
+package server
+
+import "strings"
+
+// pathMatches reports whether the request path matches the URL of a behavior.
+// Segments written as `{name}` are path templates and match any single non-empty segment.
+func pathMatches(pattern, path string) bool {
+	if pattern == path {
+		return true
+	}
+	if !strings.Contains(pattern, "{") {
+		return false
+	}
+
+	patternSegments := strings.Split(pattern, "/")
+	pathSegments := strings.Split(path, "/")
+	if len(patternSegments) != len(pathSegments) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		if isPathParameter(segment) {
+			if pathSegments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != pathSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isPathParameter reports whether a path segment is a template parameter like `{id}`.
+func isPathParameter(segment string) bool {
+	return len(segment) > 2 && strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
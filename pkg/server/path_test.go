@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathMatches(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"/users", "/users", true},
+		{"/users", "/users/1", false},
+		{"/users/{id}", "/users/1", true},
+		{"/users/{id}", "/users/", false},
+		{"/users/{id}", "/users/1/posts", false},
+		{"/users/{id}/posts/{postId}", "/users/1/posts/2", true},
+		{"/users/{id}/posts", "/users/1/comments", false},
+		{"/users/{}", "/users/1", false},
+	} {
+		assert.Equal(t, tc.match, pathMatches(tc.pattern, tc.path), tc.pattern+" "+tc.path)
+	}
+}
+
+func TestHandleRequest_PathTemplate(t *testing.T) {
+	body := "user"
+	beh := &model.Behavior{
+		Method:           http.MethodGet,
+		URL:              "/users/{id}",
+		ResponseBehavior: &model.ResponseBehavior{Body: &body},
+	}
+	ts := newTestServer([]*model.Behavior{beh}, nil)
+
+	rr := httptest.NewRecorder()
+	ts.handleRequest(rr, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, body, rr.Body.String())
+}
+
+func TestServeGraphQL_PathTemplate(t *testing.T) {
+	beh := graphQLBehavior(nil, &model.GraphQLResponse{Data: strPtr(`{"tenant":true}`)})
+	beh.URL = "/tenants/{id}/graphql"
+	ts := newTestServer([]*model.Behavior{beh}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/tenants/7/graphql", strings.NewReader(`{"query":"{ tenant }"}`))
+	rr := httptest.NewRecorder()
+	ts.handleRequest(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"data":{"tenant":true}}`, rr.Body.String())
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+// proxyRequest forwards the request to the upstream of the given behavior.
+// Proxy headers are set on the forwarded request while regular headers
+// are set on the upstream response.
+func proxyRequest(w http.ResponseWriter, r *http.Request, behavior *model.ResponseBehavior) {
+	upstream, err := url.Parse(*behavior.Proxy)
+	if err != nil {
+		http.Error(w, "Bad Gateway", http.StatusBadGateway)
+		return
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(upstream)
+			pr.SetXForwarded()
+			for key, value := range behavior.ProxyHeaders {
+				if http.CanonicalHeaderKey(key) == "Host" {
+					pr.Out.Host = value
+					continue
+				}
+				pr.Out.Header.Set(key, value)
+			}
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			for key, value := range behavior.Headers {
+				resp.Header.Set(key, value)
+			}
+			return nil
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"math/rand/v2"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+// randomFloat returns a pseudo-random number in [0.0,1.0) from the server's random source.
+func (s *Server) randomFloat() float64 {
+	s.randomMutex.Lock()
+	defer s.randomMutex.Unlock()
+	if s.random == nil {
+		s.random = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())) //nolint:gosec
+	}
+	return s.random.Float64()
+}
+
+// randomNormFloat returns a normally distributed number with mean 0 and stddev 1 from the server's random source.
+func (s *Server) randomNormFloat() float64 {
+	s.randomMutex.Lock()
+	defer s.randomMutex.Unlock()
+	if s.random == nil {
+		s.random = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())) //nolint:gosec
+	}
+	return s.random.NormFloat64()
+}
+
+// pickVariant returns one of the weighted variants or the behavior itself if it has none.
+func (s *Server) pickVariant(behavior *model.ResponseBehavior) *model.ResponseBehavior {
+	if len(behavior.Variants) == 0 {
+		return behavior
+	}
+
+	total := uint(0)
+	for _, variant := range behavior.Variants {
+		total += variant.Weight
+	}
+
+	roll := s.randomFloat() * float64(total)
+	cumulative := 0.0
+	for _, variant := range behavior.Variants {
+		cumulative += float64(variant.Weight)
+		if roll < cumulative {
+			return variant.ResponseBehavior
+		}
+	}
+	return behavior.Variants[len(behavior.Variants)-1].ResponseBehavior
+}
@@ -0,0 +1,53 @@
+package server
+
+import "github.com/StevenCyb/ServMock/pkg/model"
+
+// nextStep returns the response of the current sequence step and advances the sequence.
+// Behaviors without steps always respond with their own response behavior.
+func nextStep(behavior *model.Behavior) *model.ResponseBehavior {
+	if len(behavior.Steps) == 0 {
+		return behavior.ResponseBehavior
+	}
+
+	total := uint(0)
+	for _, step := range behavior.Steps {
+		total += step.Count
+	}
+
+	position := behavior.Hits
+	if position >= total {
+		if !behavior.Loop {
+			return behavior.Steps[len(behavior.Steps)-1].ResponseBehavior
+		}
+		position %= total
+		behavior.Hits = position
+	}
+	behavior.Hits++
+
+	for _, step := range behavior.Steps {
+		if position < step.Count {
+			return step.ResponseBehavior
+		}
+		position -= step.Count
+	}
+	return behavior.Steps[len(behavior.Steps)-1].ResponseBehavior
+}
+
+// resetSequences rewinds the sequences of all behaviors matching the given method and URL.
+// Empty filters match every behavior. It returns the number of rewound sequences.
+func (s *Server) resetSequences(method, url string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	reset := 0
+	for _, behavior := range s.behaviorSet.Behaviors {
+		if len(behavior.Steps) == 0 ||
+			(method != "" && behavior.Method != model.HTTPMethod(method)) ||
+			(url != "" && behavior.URL != url) {
+			continue
+		}
+		behavior.Hits = 0
+		reset++
+	}
+	return reset
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/StevenCyb/ServMock/pkg/setup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newJobBehavior(loop bool) *model.Behavior {
+	accepted := uint16(http.StatusAccepted)
+	ok := uint16(http.StatusOK)
+	return &model.Behavior{
+		Method:           http.MethodGet,
+		URL:              "/job/1",
+		ResponseBehavior: &model.ResponseBehavior{},
+		Loop:             loop,
+		Steps: []*model.Step{
+			{Count: 2, ResponseBehavior: &model.ResponseBehavior{StatusCode: &accepted}},
+			{Count: 1, ResponseBehavior: &model.ResponseBehavior{StatusCode: &ok}},
+		},
+	}
+}
+
+func requestCodes(ts *mockServer, n int) []int {
+	codes := make([]int, 0, n)
+	for range n {
+		r := httptest.NewRequest(http.MethodGet, "/job/1", nil)
+		w := httptest.NewRecorder()
+		ts.handleRequest(w, r)
+		codes = append(codes, w.Code)
+	}
+	return codes
+}
+
+func TestSequence_Stick(t *testing.T) {
+	ts := newTestServer([]*model.Behavior{newJobBehavior(false)}, nil)
+	assert.Equal(t, []int{202, 202, 200, 200, 200}, requestCodes(ts, 5))
+}
+
+func TestSequence_Loop(t *testing.T) {
+	ts := newTestServer([]*model.Behavior{newJobBehavior(true)}, nil)
+	assert.Equal(t, []int{202, 202, 200, 202, 202, 200, 202}, requestCodes(ts, 7))
+}
+
+func TestSequence_Reset(t *testing.T) {
+	ts := newTestServer([]*model.Behavior{newJobBehavior(false)}, nil)
+	assert.Equal(t, []int{202, 202, 200}, requestCodes(ts, 3))
+
+	r := httptest.NewRequest(http.MethodPost, "/__servmock/sequences/reset?path=/other", nil)
+	w := httptest.NewRecorder()
+	ts.handleRequest(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"reset":0}`, w.Body.String())
+	assert.Equal(t, []int{200}, requestCodes(ts, 1))
+
+	r = httptest.NewRequest(http.MethodPost, "/__servmock/sequences/reset?method=get&path=/job/1", nil)
+	w = httptest.NewRecorder()
+	ts.handleRequest(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"reset":1}`, w.Body.String())
+	assert.Equal(t, []int{202, 202, 200}, requestCodes(ts, 3))
+}
+
+func TestAdmin_MethodNotAllowedAndUnknown(t *testing.T) {
+	ts := newTestServer(nil, nil)
+	r := httptest.NewRequest(http.MethodGet, "/__servmock/sequences/reset", nil)
+	w := httptest.NewRecorder()
+	ts.handleRequest(w, r)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	r = httptest.NewRequest(http.MethodGet, "/__servmock/unknown", nil)
+	w = httptest.NewRecorder()
+	ts.handleRequest(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSequence_WithVariants(t *testing.T) {
+	raw := `
+[GET /job/1]
+variant = 1
+body = parent-variant
+step = 1
+body = step-one
+step = 1
+body = step-two
+variant = 1
+status_code = 201
+`
+	sections, err := ini.Parse(strings.NewReader(raw), true)
+	require.NoError(t, err)
+	bs, err := setup.Build(sections)
+	require.NoError(t, err)
+
+	ts := newTestServer(bs.Behaviors, nil)
+	var bodies []string
+	var codes []int
+	for range 3 {
+		w := httptest.NewRecorder()
+		ts.handleRequest(w, httptest.NewRequest(http.MethodGet, "/job/1", nil))
+		bodies = append(bodies, w.Body.String())
+		codes = append(codes, w.Code)
+	}
+	// The variant of the behavior does not apply to the steps, the one of the second step does.
+	assert.Equal(t, []string{"step-one", "step-two", "step-two"}, bodies)
+	assert.Equal(t, []int{200, 201, 201}, codes)
+}
@@ -4,10 +4,16 @@ import (
 	"context"
 	"errors"
 	"log"
+	"math/rand/v2"
+	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/StevenCyb/ServMock/pkg/model"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
 const readWriteTimeout = 30 * time.Second
@@ -15,7 +21,16 @@ const idleTimeout = 60 * time.Second
 
 type Server struct {
 	http.Server
-	behaviorSet *model.BehaviorSet
+	behaviorSet  *model.BehaviorSet
+	descriptors  *protoregistry.Files
+	validation   *Validation
+	explainMode  ExplainMode
+	reloadStatus ReloadStatus
+	mutex        sync.Mutex
+	random       *rand.Rand
+	randomMutex  sync.Mutex
+	baseContext  context.Context //nolint:containedctx
+	cancelBase   context.CancelFunc
 }
 
 // New creates a new Server instance with the specified listen address.
@@ -29,16 +44,30 @@ func New(listen string, behaviorSet *model.BehaviorSet) *Server {
 		},
 		behaviorSet: behaviorSet,
 	}
-	server.Handler = http.HandlerFunc(server.handleRequest)
+	// Requests derive their context from a base context that is canceled on shutdown,
+	// so that pending delays and throttled writes do not hold up the shutdown.
+	server.baseContext, server.cancelBase = context.WithCancel(context.Background())
+	server.BaseContext = func(net.Listener) context.Context { return server.baseContext }
+	// Serve HTTP/2 without TLS (h2c) as well, which is required by gRPC clients.
+	server.Handler = h2c.NewHandler(http.HandlerFunc(server.handleRequest), &http2.Server{})
 
 	return server
 }
 
 // handleRequest processes incoming HTTP requests and serves mock responses based on the behavior set.
 func (s *Server) SetBehaviorSet(behaviorSet *model.BehaviorSet) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	s.behaviorSet = behaviorSet
 }
 
+// SetSeed makes probabilistic responses reproducible by seeding the random source.
+func (s *Server) SetSeed(seed uint64) {
+	s.randomMutex.Lock()
+	defer s.randomMutex.Unlock()
+	s.random = rand.New(rand.NewPCG(seed, seed)) //nolint:gosec
+}
+
 // handleRequest is the main request handler for the server.
 func (s *Server) Start() <-chan error {
 	errorChan := make(chan error, 1)
@@ -54,6 +83,9 @@ func (s *Server) Start() <-chan error {
 
 // Shutdown gracefully stops the server, allowing for ongoing requests to complete.
 func (s *Server) Shutdown(ctx context.Context) error {
+	if s.cancelBase != nil {
+		s.cancelBase()
+	}
 	if err := s.Server.Shutdown(ctx); err != nil {
 		return err
 	}
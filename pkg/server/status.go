@@ -0,0 +1,40 @@
+package server
+
+import "time"
+
+// ReloadStatus describes the outcome of the configuration loads.
+type ReloadStatus struct {
+	// Healthy reports whether the latest load succeeded.
+	Healthy bool `json:"healthy"`
+	// LastSuccess is the time the active configuration was loaded.
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	// LastError is the error of the latest failed load, which is kept after later successful loads.
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+	// ConfigHash is the SHA-256 hash of the active configuration.
+	ConfigHash string `json:"config_hash,omitempty"`
+}
+
+// ReportReload records the outcome of a configuration load, hash is the hash of the loaded configuration.
+// A failed load keeps the hash of the active configuration.
+func (s *Server) ReportReload(hash string, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	s.reloadStatus.Healthy = err == nil
+	if err != nil {
+		s.reloadStatus.LastError = err.Error()
+		s.reloadStatus.LastErrorAt = &now
+		return
+	}
+	s.reloadStatus.LastSuccess = &now
+	s.reloadStatus.ConfigHash = hash
+}
+
+// ReloadStatus returns the outcome of the configuration loads.
+func (s *Server) ReloadStatus() ReloadStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.reloadStatus
+}
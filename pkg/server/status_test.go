@@ -0,0 +1,55 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getStatus(t *testing.T, ts *mockServer) ReloadStatus {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	ts.handleRequest(rr, httptest.NewRequest(http.MethodGet, "/__servmock/status", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var status ReloadStatus
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &status))
+	return status
+}
+
+func TestReloadStatus(t *testing.T) {
+	ts := newTestServer(nil, nil)
+	assert.Equal(t, ReloadStatus{}, getStatus(t, ts))
+
+	ts.ReportReload("abc", nil)
+	status := getStatus(t, ts)
+	assert.True(t, status.Healthy)
+	assert.Equal(t, "abc", status.ConfigHash)
+	assert.NotNil(t, status.LastSuccess)
+	assert.Empty(t, status.LastError)
+
+	ts.ReportReload("def", errors.New("Malformed property at line 3: x=y"))
+	status = getStatus(t, ts)
+	assert.False(t, status.Healthy)
+	assert.Equal(t, "abc", status.ConfigHash)
+	assert.Equal(t, "Malformed property at line 3: x=y", status.LastError)
+	assert.NotNil(t, status.LastErrorAt)
+
+	ts.ReportReload("ghi", nil)
+	status = getStatus(t, ts)
+	assert.True(t, status.Healthy)
+	assert.Equal(t, "ghi", status.ConfigHash)
+	assert.Equal(t, "Malformed property at line 3: x=y", status.LastError)
+}
+
+func TestReloadStatus_MethodNotAllowed(t *testing.T) {
+	ts := newTestServer(nil, nil)
+	rr := httptest.NewRecorder()
+	ts.handleRequest(rr, httptest.NewRequest(http.MethodPost, "/__servmock/status", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
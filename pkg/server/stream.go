@@ -0,0 +1,105 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+const defaultPartContentType = "text/plain"
+
+// writeStream serves the behavior as a sequence of individually flushed chunks
+// framed according to the stream mode.
+//
+//nolint:cyclop
+func writeStream(w http.ResponseWriter, r *http.Request, statusCode int, behavior *model.ResponseBehavior) {
+	// Streams may last longer than the write timeout of the server, e.g. with delays or keep-alive.
+	clearWriteDeadline(w)
+	if behavior.SSE {
+		writeEventStream(w, r, statusCode, behavior)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	stream := behavior.Stream
+	switch stream.Mode {
+	case model.StreamNDJSON:
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+		}
+	case model.StreamMultipart:
+		w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary="+stream.Boundary)
+	case model.StreamChunked, model.StreamSSE:
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(statusCode)
+	flusher.Flush()
+
+	ctx := r.Context()
+	for i, chunk := range streamChunks(behavior) {
+		pause := time.Duration(0)
+		if chunk.Delay != nil {
+			pause = *chunk.Delay
+		} else if i > 0 && behavior.ChunkDelay != nil {
+			pause = *behavior.ChunkDelay
+		}
+		if !sleep(ctx, pause) {
+			return
+		}
+		if !writeThrottled(ctx, w, []byte(frameChunk(stream, chunk)), behavior) {
+			return
+		}
+	}
+
+	if stream.Mode == model.StreamMultipart {
+		writeThrottled(ctx, w, []byte("--"+stream.Boundary+"--\r\n"), behavior)
+	}
+}
+
+// streamChunks returns the declared chunks or derives them from the body.
+// NDJSON bodies are split by lines, other bodies are sent as a single chunk.
+func streamChunks(behavior *model.ResponseBehavior) []*model.Chunk {
+	if behavior.Stream != nil && len(behavior.Stream.Chunks) > 0 {
+		return behavior.Stream.Chunks
+	}
+	if behavior.Body == nil {
+		return nil
+	}
+	if behavior.Stream != nil && behavior.Stream.Mode == model.StreamNDJSON {
+		chunks := []*model.Chunk{}
+		for _, line := range strings.Split(*behavior.Body, "\n") {
+			if strings.TrimSpace(line) != "" {
+				chunks = append(chunks, &model.Chunk{Data: line})
+			}
+		}
+		return chunks
+	}
+	return []*model.Chunk{{Data: *behavior.Body}}
+}
+
+// frameChunk renders a chunk according to the stream mode.
+func frameChunk(stream *model.Stream, chunk *model.Chunk) string {
+	switch stream.Mode {
+	case model.StreamNDJSON:
+		return chunk.Data + "\n"
+	case model.StreamMultipart:
+		contentType := chunk.ContentType
+		if contentType == "" {
+			contentType = defaultPartContentType
+		}
+		return "--" + stream.Boundary + "\r\n" +
+			"Content-Type: " + contentType + "\r\n" +
+			"Content-Length: " + strconv.Itoa(len(chunk.Data)) + "\r\n\r\n" +
+			chunk.Data + "\r\n"
+	case model.StreamChunked, model.StreamSSE:
+	}
+	return chunk.Data
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamServer(behavior *model.ResponseBehavior) *mockServer {
+	beh := &model.Behavior{
+		Method:           http.MethodGet,
+		URL:              "/stream",
+		ResponseBehavior: behavior,
+	}
+	return newTestServer([]*model.Behavior{beh}, nil)
+}
+
+func TestStream_Chunked(t *testing.T) {
+	delay := 30 * time.Millisecond
+	ts := newStreamServer(&model.ResponseBehavior{Stream: &model.Stream{
+		Mode:   model.StreamChunked,
+		Chunks: []*model.Chunk{{Data: "first"}, {Data: "second", Delay: &delay}},
+	}})
+	srv := httptest.NewServer(http.HandlerFunc(ts.handleRequest))
+	defer srv.Close()
+
+	start := time.Now()
+	resp, err := http.Get(srv.URL + "/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, []string{"chunked"}, resp.TransferEncoding)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "firstsecond", string(body))
+	assert.GreaterOrEqual(t, time.Since(start), delay)
+}
+
+func TestStream_NDJSONFromBody(t *testing.T) {
+	body := "{\"a\":1}\n\n{\"b\":2}"
+	ts := newStreamServer(&model.ResponseBehavior{Body: &body, Stream: &model.Stream{Mode: model.StreamNDJSON}})
+	r := httptest.NewRequest(http.MethodGet, "/stream", nil)
+	w := &chunkRecorder{ResponseRecorder: httptest.NewRecorder()}
+	ts.handleRequest(w, r)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Equal(t, []string{"{\"a\":1}\n", "{\"b\":2}\n"}, w.chunks)
+}
+
+func TestStream_Multipart(t *testing.T) {
+	ts := newStreamServer(&model.ResponseBehavior{Stream: &model.Stream{
+		Mode:     model.StreamMultipart,
+		Boundary: "frame",
+		Chunks:   []*model.Chunk{{Data: "one"}, {Data: "{}", ContentType: "application/json"}},
+	}})
+	r := httptest.NewRequest(http.MethodGet, "/stream", nil)
+	w := httptest.NewRecorder()
+	ts.handleRequest(w, r)
+
+	mediaType, params, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/x-mixed-replace", mediaType)
+	reader := multipart.NewReader(w.Body, params["boundary"])
+
+	part, err := reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", part.Header.Get("Content-Type"))
+	data, _ := io.ReadAll(part)
+	assert.Equal(t, "one", string(data))
+
+	part, err = reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", part.Header.Get("Content-Type"))
+	data, _ = io.ReadAll(part)
+	assert.Equal(t, "{}", string(data))
+
+	_, err = reader.NextPart()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestStream_SSEFromChunks(t *testing.T) {
+	ts := newStreamServer(&model.ResponseBehavior{SSE: true, Stream: &model.Stream{
+		Mode:   model.StreamSSE,
+		Chunks: []*model.Chunk{{Data: "a"}, {Data: "b"}},
+	}})
+	r := httptest.NewRequest(http.MethodGet, "/stream", nil)
+	w := httptest.NewRecorder()
+	ts.handleRequest(w, r)
+	assert.Equal(t, "data: a\n\ndata: b\n\n", w.Body.String())
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+const throttleTicksPerSecond = 10
+
+// sleep waits for the given duration and returns false if the context got canceled before.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// clearWriteDeadline lifts the write timeout of the server for responses meant to take long,
+// like streams and throttled bodies. Writers without deadline support are left as they are.
+func clearWriteDeadline(w http.ResponseWriter) {
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+}
+
+// writeThrottled writes the body in chunks, pausing between them according to the
+// chunk delay and bandwidth of the behavior. It returns false if the client went away
+// or the server is shutting down.
+func writeThrottled(ctx context.Context, w http.ResponseWriter, body []byte, behavior *model.ResponseBehavior) bool {
+	if behavior.ChunkDelay != nil || behavior.Bandwidth != nil {
+		clearWriteDeadline(w)
+	}
+	chunkSize := len(body)
+	if behavior.ChunkSize != nil {
+		chunkSize = int(*behavior.ChunkSize) //nolint:gosec
+	} else if behavior.Bandwidth != nil {
+		chunkSize = int(max(1, *behavior.Bandwidth/throttleTicksPerSecond)) //nolint:gosec
+	}
+	chunkSize = max(1, chunkSize)
+
+	flusher, _ := w.(http.Flusher)
+	for first := true; len(body) > 0; first = false {
+		n := min(chunkSize, len(body))
+
+		pause := time.Duration(0)
+		if !first && behavior.ChunkDelay != nil {
+			pause = *behavior.ChunkDelay
+		}
+		if !sleep(ctx, pause) {
+			return false
+		}
+
+		if _, err := w.Write(body[:n]); err != nil {
+			return false
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		body = body[n:]
+
+		// Hold back the next chunk until the bandwidth allows sending it.
+		if behavior.Bandwidth != nil && len(body) > 0 {
+			if !sleep(ctx, time.Duration(n)*time.Second/time.Duration(*behavior.Bandwidth)) { //nolint:gosec
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type chunkRecorder struct {
+	*httptest.ResponseRecorder
+	chunks []string
+}
+
+func (c *chunkRecorder) Write(p []byte) (int, error) {
+	c.chunks = append(c.chunks, string(p))
+	return c.ResponseRecorder.Write(p)
+}
+
+func TestSleep_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	assert.False(t, sleep(ctx, time.Second))
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	assert.True(t, sleep(context.Background(), 0))
+}
+
+func TestWriteThrottled_ChunkSizeAndDelay(t *testing.T) {
+	chunkSize := uint64(4)
+	chunkDelay := 20 * time.Millisecond
+	w := &chunkRecorder{ResponseRecorder: httptest.NewRecorder()}
+	start := time.Now()
+	ok := writeThrottled(context.Background(), w, []byte("0123456789"), &model.ResponseBehavior{
+		ChunkSize:  &chunkSize,
+		ChunkDelay: &chunkDelay,
+	})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"0123", "4567", "89"}, w.chunks)
+	assert.GreaterOrEqual(t, time.Since(start), 2*chunkDelay)
+}
+
+func TestWriteThrottled_Bandwidth(t *testing.T) {
+	bandwidth := uint64(100)
+	w := &chunkRecorder{ResponseRecorder: httptest.NewRecorder()}
+	start := time.Now()
+	ok := writeThrottled(context.Background(), w, make([]byte, 50), &model.ResponseBehavior{Bandwidth: &bandwidth})
+	assert.True(t, ok)
+	assert.Len(t, w.chunks, 5)
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+}
+
+func TestWriteThrottled_Canceled(t *testing.T) {
+	chunkSize := uint64(1)
+	chunkDelay := time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	w := &chunkRecorder{ResponseRecorder: httptest.NewRecorder()}
+	ok := writeThrottled(ctx, w, []byte("abc"), &model.ResponseBehavior{ChunkSize: &chunkSize, ChunkDelay: &chunkDelay})
+	assert.False(t, ok)
+	assert.Equal(t, []string{"a"}, w.chunks)
+}
+
+func TestHandleRequest_DelayCanceledByClient(t *testing.T) {
+	d := time.Minute
+	beh := &model.Behavior{
+		Method:           http.MethodGet,
+		URL:              "/delay",
+		ResponseBehavior: &model.ResponseBehavior{Delay: &d},
+	}
+	ts := newTestServer([]*model.Behavior{beh}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	r := httptest.NewRequest(http.MethodGet, "/delay", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	start := time.Now()
+	ts.handleRequest(w, r)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestShutdown_CancelsDelays(t *testing.T) {
+	d := time.Minute
+	s := New("127.0.0.1:0", &model.BehaviorSet{Behaviors: []*model.Behavior{{
+		Method:           http.MethodGet,
+		URL:              "/delay",
+		ResponseBehavior: &model.ResponseBehavior{Delay: &d},
+	}}})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go s.Serve(listener)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resp, reqErr := http.Get("http://" + listener.Addr().String() + "/delay")
+		if reqErr == nil {
+			resp.Body.Close()
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	start := time.Now()
+	require.NoError(t, s.Shutdown(ctx))
+	assert.Less(t, time.Since(start), 2*time.Second)
+	<-done
+}
+
+func TestHandleRequest_OutlivesWriteTimeout(t *testing.T) {
+	delay := 150 * time.Millisecond
+	chunkSize := uint64(2)
+	interval := 100 * time.Millisecond
+	tests := []struct {
+		name     string
+		behavior *model.ResponseBehavior
+		expected string
+	}{
+		{
+			name:     "Delay",
+			behavior: &model.ResponseBehavior{Delay: &delay, Body: strPtr("late")},
+			expected: "late",
+		},
+		{
+			name:     "ChunkDelay",
+			behavior: &model.ResponseBehavior{ChunkSize: &chunkSize, ChunkDelay: &interval, Body: strPtr("abcdef")},
+			expected: "abcdef",
+		},
+		{
+			name: "ChunkedStream",
+			behavior: &model.ResponseBehavior{Stream: &model.Stream{Mode: model.StreamChunked, Chunks: []*model.Chunk{
+				{Data: "a"}, {Data: "b", Delay: &delay}, {Data: "c", Delay: &delay},
+			}}},
+			expected: "abc",
+		},
+		{
+			name: "EventStream",
+			behavior: &model.ResponseBehavior{SSE: true, EventStream: &model.EventStream{
+				Interval: &interval,
+				Events:   []*model.Event{{Data: []string{"1"}}, {Data: []string{"2"}}, {Data: []string{"3"}}},
+			}},
+			expected: "data: 1\n\ndata: 2\n\ndata: 3\n\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := newTestServer([]*model.Behavior{{Method: http.MethodGet, URL: "/slow", ResponseBehavior: test.behavior}}, nil)
+			srv := httptest.NewUnstartedServer(http.HandlerFunc(ts.handleRequest))
+			srv.Config.WriteTimeout = 100 * time.Millisecond
+			srv.Start()
+			defer srv.Close()
+
+			resp, err := http.Get(srv.URL + "/slow")
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(body))
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/StevenCyb/ServMock/pkg/openapi"
+)
+
+// Validation configures how incoming requests are checked against an OpenAPI spec.
+// Invalid requests are rejected with StatusCode and the violations, or only logged if LogOnly is set.
+type Validation struct {
+	Validator  *openapi.Validator
+	LogOnly    bool
+	StatusCode int
+}
+
+// validationErrorResponse is the body of rejected requests.
+type validationErrorResponse struct {
+	Error      string   `json:"error"`
+	Violations []string `json:"violations"`
+}
+
+// SetValidation enables request validation, nil disables it.
+func (s *Server) SetValidation(validation *Validation) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.validation = validation
+}
+
+// validateRequest checks the request against the OpenAPI spec before behavior matching.
+// Only requests served by plain HTTP behaviors are checked, see servesPlainHTTP.
+// It reports whether the request may be served, rejected requests are already answered.
+func (s *Server) validateRequest(w http.ResponseWriter, r *http.Request) bool {
+	s.mutex.Lock()
+	validation := s.validation
+	s.mutex.Unlock()
+	if validation == nil || !s.servesPlainHTTP(r) {
+		return true
+	}
+
+	violations := validation.Validator.Validate(r)
+	if len(violations) == 0 {
+		return true
+	}
+
+	if validation.LogOnly {
+		log.Printf("Request validation failed for %s %s: %s", r.Method, r.URL.Path, strings.Join(violations, "; "))
+		return true
+	}
+
+	statusCode := validation.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusBadRequest
+	}
+	writeJSON(w, statusCode, validationErrorResponse{
+		Error:      "Request does not match the OpenAPI spec",
+		Violations: violations,
+	})
+	return false
+}
+
+// servesPlainHTTP reports whether the request would be answered by a plain HTTP response,
+// without counting it as a hit. GraphQL, gRPC, WebSocket and proxied requests are not
+// described by the spec, so they are not validated.
+func (s *Server) servesPlainHTTP(r *http.Request) bool {
+	if s.servesGraphQL(r) {
+		return false
+	}
+	body := s.matchingBody(r)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, behavior := range s.behaviorSet.Behaviors {
+		if methodMatches(behavior.Method, r) && pathMatches(behavior.URL, r.URL.Path) &&
+			requestMatches(behavior, r, body) {
+			return behavior.Method != model.MethodWS && behavior.Method != model.MethodGRPC &&
+				isPlainHTTP(behavior.ResponseBehavior) &&
+				!slices.ContainsFunc(behavior.Steps, func(step *model.Step) bool {
+					return !isPlainHTTP(step.ResponseBehavior)
+				})
+		}
+	}
+	return s.behaviorSet.DefaultBehavior == nil || isPlainHTTP(s.behaviorSet.DefaultBehavior)
+}
+
+// isPlainHTTP reports whether the response is neither a gRPC, WebSocket nor proxied one.
+func isPlainHTTP(responseBehavior *model.ResponseBehavior) bool {
+	return responseBehavior.GRPC == nil && responseBehavior.WebSocket == nil && responseBehavior.Proxy == nil
+}
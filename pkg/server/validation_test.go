@@ -0,0 +1,129 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/StevenCyb/ServMock/pkg/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPetValidator(t *testing.T) *openapi.Validator {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: Pet
+`), 0o600))
+	doc, err := openapi.Load(path)
+	require.NoError(t, err)
+	validator, err := openapi.NewValidator(doc)
+	require.NoError(t, err)
+	return validator
+}
+
+func newPetServer(t *testing.T, validation *Validation) *mockServer {
+	t.Helper()
+	body := `{"id":1}`
+	ts := newTestServer([]*model.Behavior{{
+		Method:           http.MethodGet,
+		URL:              "/pets/{id}",
+		ResponseBehavior: &model.ResponseBehavior{Body: &body},
+	}}, nil)
+	ts.SetValidation(validation)
+	return ts
+}
+
+func TestValidateRequest_Reject(t *testing.T) {
+	ts := newPetServer(t, &Validation{Validator: newPetValidator(t), StatusCode: http.StatusUnprocessableEntity})
+
+	rr := httptest.NewRecorder()
+	ts.handleRequest(rr, httptest.NewRequest(http.MethodGet, "/pets/1", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	ts.handleRequest(rr, httptest.NewRequest(http.MethodGet, "/pets/abc", nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), `path parameter \"id\"`)
+}
+
+func TestValidateRequest_DefaultStatus(t *testing.T) {
+	ts := newPetServer(t, &Validation{Validator: newPetValidator(t)})
+
+	rr := httptest.NewRecorder()
+	ts.handleRequest(rr, httptest.NewRequest(http.MethodGet, "/pets/abc", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestValidateRequest_LogOnly(t *testing.T) {
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	ts := newPetServer(t, &Validation{Validator: newPetValidator(t), LogOnly: true})
+
+	rr := httptest.NewRecorder()
+	ts.handleRequest(rr, httptest.NewRequest(http.MethodGet, "/pets/abc", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, output.String(), "Request validation failed for GET /pets/abc")
+}
+
+func TestValidateRequest_SkipsNonHTTPBehaviors(t *testing.T) {
+	upstream := "http://127.0.0.1:0"
+	ts := newTestServer([]*model.Behavior{
+		graphQLBehavior(nil, &model.GraphQLResponse{Data: strPtr(`{"ok":true}`)}),
+		{Method: model.MethodGRPC, URL: "/pets.Pets/Get", ResponseBehavior: &model.ResponseBehavior{GRPC: &model.GRPCResponse{}}},
+		{Method: model.MethodWS, URL: "/socket", ResponseBehavior: &model.ResponseBehavior{WebSocket: &model.WebSocketScript{}}},
+		{Method: http.MethodGet, URL: "/upstream", ResponseBehavior: &model.ResponseBehavior{Proxy: &upstream}},
+		{Method: http.MethodGet, URL: "/pets/{id}", ResponseBehavior: &model.ResponseBehavior{}},
+	}, nil)
+	ts.SetValidation(&Validation{Validator: newPetValidator(t)})
+
+	grpc := httptest.NewRequest(http.MethodPost, "/pets.Pets/Get", nil)
+	grpc.Header.Set("Content-Type", "application/grpc")
+	ws := httptest.NewRequest(http.MethodGet, "/socket", nil)
+	ws.Header.Set("Connection", "Upgrade")
+	ws.Header.Set("Upgrade", "websocket")
+
+	assert.False(t, ts.servesPlainHTTP(httptest.NewRequest(http.MethodPost, "/graphql", nil)))
+	assert.False(t, ts.servesPlainHTTP(grpc))
+	assert.False(t, ts.servesPlainHTTP(ws))
+	assert.False(t, ts.servesPlainHTTP(httptest.NewRequest(http.MethodGet, "/upstream", nil)))
+	assert.True(t, ts.servesPlainHTTP(httptest.NewRequest(http.MethodGet, "/pets/abc", nil)))
+	assert.True(t, ts.servesPlainHTTP(httptest.NewRequest(http.MethodGet, "/unknown", nil)))
+
+	rr := postGraphQL(ts, `{"query":"{ ok }"}`)
+	assert.Equal(t, http.StatusOK, rr.Code, "GraphQL requests are not validated")
+	assert.JSONEq(t, `{"data":{"ok":true}}`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	ts.handleRequest(rr, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "unmatched requests are still validated")
+}
+
+func TestValidateRequest_SkipsProxiedDefault(t *testing.T) {
+	upstream := "http://127.0.0.1:0"
+	ts := newTestServer(nil, &model.ResponseBehavior{Proxy: &upstream})
+	ts.SetValidation(&Validation{Validator: newPetValidator(t)})
+
+	assert.False(t, ts.servesPlainHTTP(httptest.NewRequest(http.MethodGet, "/unknown", nil)))
+}
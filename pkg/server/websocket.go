@@ -0,0 +1,139 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/gorilla/websocket"
+)
+
+const webSocketCloseTimeout = time.Second
+
+// methodMatches reports whether the request method matches the method of a behavior.
+// WS behaviors match GET requests that ask for a WebSocket upgrade and GRPC behaviors match gRPC calls.
+func methodMatches(method model.HTTPMethod, r *http.Request) bool {
+	switch method {
+	case model.MethodWS:
+		return r.Method == http.MethodGet && websocket.IsWebSocketUpgrade(r)
+	case model.MethodGRPC:
+		return isGRPCRequest(r)
+	}
+	return method == model.HTTPMethod(r.Method)
+}
+
+// serveWebSocket upgrades the connection and runs the script of the behavior.
+//
+//nolint:cyclop
+func serveWebSocket(w http.ResponseWriter, r *http.Request, behavior *model.ResponseBehavior) {
+	header := http.Header{}
+	for key, value := range behavior.Headers {
+		header.Set(key, value)
+	}
+	for _, cookie := range behavior.Cookies {
+		header.Add("Set-Cookie", cookie.String())
+	}
+
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	conn, err := upgrader.Upgrade(w, r, header)
+	if err != nil {
+		// The upgrader already replied with an error.
+		return
+	}
+	defer conn.Close()
+
+	script := behavior.WebSocket
+	messages := make(chan string)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(messages)
+		for {
+			_, message, readErr := conn.ReadMessage()
+			if readErr != nil {
+				return
+			}
+			select {
+			case messages <- string(message):
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for _, frame := range script.OnConnect {
+		if conn.WriteMessage(websocket.TextMessage, []byte(frame)) != nil {
+			return
+		}
+	}
+	if script.Close != nil && script.CloseAfter == nil {
+		closeWebSocket(conn, script.Close)
+		return
+	}
+
+	var ping <-chan time.Time
+	if script.Ping != nil {
+		ticker := time.NewTicker(*script.Ping)
+		defer ticker.Stop()
+		ping = ticker.C
+	}
+	var closeAfter <-chan time.Time
+	if script.CloseAfter != nil {
+		timer := time.NewTimer(*script.CloseAfter)
+		defer timer.Stop()
+		closeAfter = timer.C
+	}
+
+	for {
+		select {
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			if closeFrame, replied := replyWebSocket(conn, script.Replies, message); replied && closeFrame != nil {
+				closeWebSocket(conn, closeFrame)
+				return
+			}
+		case <-ping:
+			if conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(webSocketCloseTimeout)) != nil {
+				return
+			}
+		case <-closeAfter:
+			closeFrame := script.Close
+			if closeFrame == nil {
+				closeFrame = &model.WebSocketClose{Code: websocket.CloseNormalClosure}
+			}
+			closeWebSocket(conn, closeFrame)
+			return
+		case <-r.Context().Done():
+			closeWebSocket(conn, &model.WebSocketClose{Code: websocket.CloseGoingAway})
+			return
+		}
+	}
+}
+
+// replyWebSocket sends the frames of the first reply matching the message.
+// It returns the close frame of the reply and whether any reply matched.
+func replyWebSocket(
+	conn *websocket.Conn, replies []*model.WebSocketReply, message string,
+) (*model.WebSocketClose, bool) {
+	for _, reply := range replies {
+		match := reply.Pattern.FindStringSubmatchIndex(message)
+		if match == nil {
+			continue
+		}
+		for _, frame := range reply.Frames {
+			expanded := reply.Pattern.ExpandString(nil, frame, message, match)
+			if conn.WriteMessage(websocket.TextMessage, expanded) != nil {
+				return nil, true
+			}
+		}
+		return reply.Close, true
+	}
+	return nil, false
+}
+
+func closeWebSocket(conn *websocket.Conn, closeFrame *model.WebSocketClose) {
+	message := websocket.FormatCloseMessage(closeFrame.Code, closeFrame.Reason)
+	_ = conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(webSocketCloseTimeout))
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dialWebSocket(t *testing.T, script *model.WebSocketScript) *websocket.Conn {
+	t.Helper()
+	beh := &model.Behavior{
+		Method:           model.MethodWS,
+		URL:              "/socket",
+		ResponseBehavior: &model.ResponseBehavior{WebSocket: script},
+	}
+	ts := newTestServer([]*model.Behavior{beh}, nil)
+	srv := httptest.NewServer(http.HandlerFunc(ts.handleRequest))
+	t.Cleanup(srv.Close)
+
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/socket", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	return conn
+}
+
+func readText(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+	_, message, err := conn.ReadMessage()
+	require.NoError(t, err)
+	return string(message)
+}
+
+func TestWebSocket_Conversation(t *testing.T) {
+	conn := dialWebSocket(t, &model.WebSocketScript{
+		OnConnect: []string{`{"type":"hello"}`},
+		Replies: []*model.WebSocketReply{
+			{Pattern: regexp.MustCompile(`^subscribe:(?P<topic>\w+)$`), Frames: []string{`{"subscribed":"${topic}"}`, "ok"}},
+			{Pattern: regexp.MustCompile(`^bye$`), Frames: []string{"see you"}, Close: &model.WebSocketClose{Code: 4000, Reason: "done"}},
+		},
+	})
+
+	assert.Equal(t, `{"type":"hello"}`, readText(t, conn))
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("unmatched")))
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("subscribe:prices")))
+	assert.Equal(t, `{"subscribed":"prices"}`, readText(t, conn))
+	assert.Equal(t, "ok", readText(t, conn))
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("bye")))
+	assert.Equal(t, "see you", readText(t, conn))
+	_, _, err := conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, 4000, closeErr.Code)
+	assert.Equal(t, "done", closeErr.Text)
+}
+
+func TestWebSocket_PingAndCloseAfter(t *testing.T) {
+	ping := 20 * time.Millisecond
+	closeAfter := 150 * time.Millisecond
+	conn := dialWebSocket(t, &model.WebSocketScript{
+		Ping:       &ping,
+		CloseAfter: &closeAfter,
+		Close:      &model.WebSocketClose{Code: websocket.CloseGoingAway, Reason: "maintenance"},
+	})
+
+	pings := 0
+	conn.SetPingHandler(func(string) error {
+		pings++
+		return nil
+	})
+	_, _, err := conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, websocket.CloseGoingAway, closeErr.Code)
+	assert.Equal(t, "maintenance", closeErr.Text)
+	assert.GreaterOrEqual(t, pings, 3)
+}
+
+func TestWebSocket_CloseAfterConnect(t *testing.T) {
+	conn := dialWebSocket(t, &model.WebSocketScript{
+		OnConnect: []string{"hello"},
+		Close:     &model.WebSocketClose{Code: websocket.CloseNormalClosure},
+	})
+	assert.Equal(t, "hello", readText(t, conn))
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+}
+
+func TestWebSocket_RequiresUpgrade(t *testing.T) {
+	beh := &model.Behavior{
+		Method:           model.MethodWS,
+		URL:              "/socket",
+		ResponseBehavior: &model.ResponseBehavior{WebSocket: &model.WebSocketScript{}},
+	}
+	ts := newTestServer([]*model.Behavior{beh}, nil)
+	r := httptest.NewRequest(http.MethodGet, "/socket", nil)
+	w := httptest.NewRecorder()
+	ts.handleRequest(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -1,7 +1,13 @@
 package setup
 
 import (
+	"errors"
+	"maps"
+	"math"
 	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -11,26 +17,97 @@ import (
 )
 
 const twoParts = 2
+const probabilityTolerance = 1e-9
 
 // Build constructs a BehaviorSet from the provided sections.
-func Build(sections []ini.Section) (*model.BehaviorSet, error) {
+// All errors are collected and returned joined; problems that do not block loading are reported
+// as Warning through WithWarningHandler or WithWarnings.
+// Sections of several config files can be concatenated: behaviors keep their order, so the first
+// matching behavior wins, and all default sections are merged into one with later properties
+// overriding earlier ones. Templates are expanded afterwards, see expandTemplates.
+func Build(sections []ini.Section, opts ...Option) (*model.BehaviorSet, error) {
+	options := &buildOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
 	bs := &model.BehaviorSet{}
+	schemas := bodySchemas{}
 
+	sections, errs := expandTemplates(mergeDefaults(sections))
+	var built []builtBehavior
 	for _, section := range sections {
 		behavior, err := buildBehavior(section, bs)
 		if err != nil {
-			return nil, err
+			errs = append(errs, err)
+			continue
 		}
+		propertyOrigins := origins{}
+		var redirects []redirect
 		for _, property := range section.Properties {
+			target := propertyTarget(behavior, property)
+			propertyOrigins.record(target, property)
 			if err = propagateResponseBehavior(behavior, property); err != nil {
-				return nil, err
+				var warning *Warning
+				if errors.As(err, &warning) {
+					options.report(warning)
+					continue
+				}
+				errs = append(errs, err)
+				continue
+			}
+			if property.Key == "redirect" {
+				redirects = append(redirects, redirect{target: target, property: property})
 			}
 		}
+		inheritSteps(behavior)
+		if section.Name == "default" {
+			continue
+		}
+
+		for _, warning := range checkWarnings(built, section, behavior, redirects) {
+			options.report(warning)
+		}
+		built = append(built, builtBehavior{behavior: behavior, section: section})
+		mismatches, schemaErrs := schemas.check(section, behavior, propertyOrigins)
+		errs = append(errs, schemaErrs...)
+		if options.spec != nil {
+			mismatches = append(mismatches, checkConformance(options.spec, section, behavior, propertyOrigins)...)
+		}
+		if !options.specWarnings {
+			errs = append(errs, mismatches...)
+			continue
+		}
+		for _, mismatch := range mismatches {
+			options.report(mismatch)
+		}
 	}
 
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
 	return bs, nil
 }
 
+// mergeDefaults combines all default sections into the first one.
+func mergeDefaults(sections []ini.Section) []ini.Section {
+	merged := make([]ini.Section, 0, len(sections))
+	defaultIndex := -1
+	for _, section := range sections {
+		if section.Name != "default" {
+			merged = append(merged, section)
+			continue
+		}
+		if defaultIndex == -1 {
+			defaultIndex = len(merged)
+			section.Properties = slices.Clone(section.Properties)
+			merged = append(merged, section)
+			continue
+		}
+		merged[defaultIndex].Properties = append(merged[defaultIndex].Properties, section.Properties...)
+	}
+	return merged
+}
+
 func buildBehavior(section ini.Section, bs *model.BehaviorSet) (*model.Behavior, error) {
 	b := &model.Behavior{ResponseBehavior: &model.ResponseBehavior{}}
 	if section.Name == "default" {
@@ -39,23 +116,50 @@ func buildBehavior(section ini.Section, bs *model.BehaviorSet) (*model.Behavior,
 		if bs.Behaviors == nil {
 			bs.Behaviors = []*model.Behavior{}
 		}
-		if err := parseBehaviorHeader(b, section.Name, section.LineIndex); err != nil {
+		if err := parseBehaviorHeader(b, section); err != nil {
 			return nil, err
 		}
+		b.Origin = model.Origin{Section: section.Name, Source: section.Source, Line: section.LineIndex}
+		if b.Method == model.MethodWS {
+			b.WebSocket = &model.WebSocketScript{}
+		}
+		if b.Method == model.MethodGRPC {
+			b.GRPC = &model.GRPCResponse{}
+		}
 		bs.Behaviors = append(bs.Behaviors, b)
 	}
 	return b, nil
 }
 
-func parseBehaviorHeader(behaviors *model.Behavior, line string, lineIndex uint64) error {
+func parseBehaviorHeader(behaviors *model.Behavior, section ini.Section) error {
+	line, lineIndex, column, source := section.Name, section.LineIndex, section.Column, section.Source
 	behaviorHeader := strings.SplitN(strings.Trim(line, "[]"), " ", twoParts)
 	if len(behaviorHeader) != twoParts {
-		return &MalformedBehaviorHeaderError{Line: line, LineIndex: lineIndex}
+		return &MalformedBehaviorHeaderError{Line: line, LineIndex: lineIndex, Column: column, Source: source}
 	}
 
 	url := strings.TrimSpace(behaviorHeader[1])
+	if strings.EqualFold(behaviorHeader[0], string(model.MethodGRPC)) {
+		// gRPC methods are declared as package.Service/Method and served at /package.Service/Method.
+		url = "/" + strings.TrimPrefix(url, "/")
+		if strings.Count(url, "/") != twoParts || strings.HasSuffix(url, "/") || strings.HasPrefix(url, "//") {
+			return &MalformedBehaviorHeaderError{
+				Line:      line,
+				LineIndex: lineIndex,
+				Column:    column,
+				Source:    source,
+				Details:   Ptr("gRPC method must be in the format package.Service/Method"),
+			}
+		}
+	}
 	if url == "" || !strings.HasPrefix(url, "/") {
-		return &MalformedBehaviorHeaderError{Line: line, LineIndex: lineIndex, Details: Ptr("URL cannot be empty")}
+		return &MalformedBehaviorHeaderError{
+			Line:      line,
+			LineIndex: lineIndex,
+			Column:    column,
+			Source:    source,
+			Details:   Ptr("URL cannot be empty"),
+		}
 	}
 
 	method, match := model.HTTPMethodFromString(behaviorHeader[0])
@@ -63,6 +167,8 @@ func parseBehaviorHeader(behaviors *model.Behavior, line string, lineIndex uint6
 		return &MalformedBehaviorHeaderError{
 			Line:      line,
 			LineIndex: lineIndex,
+			Column:    column,
+			Source:    source,
 			Details:   Ptr("Invalid HTTP method: " + behaviorHeader[0]),
 		}
 	}
@@ -73,42 +179,141 @@ func parseBehaviorHeader(behaviors *model.Behavior, line string, lineIndex uint6
 	return nil
 }
 
+// enclosingResponses returns the responses a step or variant inherits from, innermost first.
+func enclosingResponses(behavior *model.Behavior, target *model.ResponseBehavior) []*model.ResponseBehavior {
+	for _, variant := range behavior.Variants {
+		if variant.ResponseBehavior == target {
+			return []*model.ResponseBehavior{behavior.ResponseBehavior}
+		}
+	}
+	for _, step := range behavior.Steps {
+		if step.ResponseBehavior == target {
+			return []*model.ResponseBehavior{behavior.ResponseBehavior}
+		}
+		for _, variant := range step.Variants {
+			if variant.ResponseBehavior == target {
+				return []*model.ResponseBehavior{step.ResponseBehavior, behavior.ResponseBehavior}
+			}
+		}
+	}
+	return nil
+}
+
+// propertyTarget returns the response behavior a property applies to.
+// Properties following a step or variant belong to it.
+func propertyTarget(behavior *model.Behavior, property ini.Property) *model.ResponseBehavior {
+	target := behavior.ResponseBehavior
+	if n := len(behavior.Steps); n > 0 {
+		target = behavior.Steps[n-1].ResponseBehavior
+	}
+	if n := len(target.Variants); n > 0 && property.Key != "variant" {
+		target = target.Variants[n-1].ResponseBehavior
+	}
+	return target
+}
+
 func propagateResponseBehavior(behavior *model.Behavior, property ini.Property) error {
+	target := propertyTarget(behavior, property)
+
 	switch property.Key {
 	case "status_code":
-		if err := parseStatusCode(behavior.ResponseBehavior, property); err != nil {
+		if err := parseStatusCode(target, property); err != nil {
 			return err
 		}
 	case "body":
-		behavior.ResponseBehavior.Body = Ptr(property.Value)
+		target.Body = Ptr(property.Value)
+	case "body_schema":
+		if err := parseBodySchema(target, property); err != nil {
+			return err
+		}
 	case "delay":
-		if err := parseDelay(behavior.ResponseBehavior, property); err != nil {
+		if err := parseDelay(target, property); err != nil {
+			return err
+		}
+	case "chunk_delay":
+		if err := parseChunkDelay(target, property); err != nil {
+			return err
+		}
+	case "chunk_size":
+		if err := parseChunkSize(target, property); err != nil {
+			return err
+		}
+	case "bandwidth":
+		if err := parseBandwidth(target, property); err != nil {
 			return err
 		}
 	case "header":
-		if err := parseHeaderAttribute(behavior.ResponseBehavior, property); err != nil {
+		if err := parseHeaderAttribute(target, property); err != nil {
 			return err
 		}
 	case "redirect":
-		behavior.ResponseBehavior.Body = Ptr(property.Value)
+		target.Body = Ptr(property.Value)
 	case "sse":
-		behavior.ResponseBehavior.SSE = strings.ToLower(property.Value) == "true"
+		target.SSE = strings.ToLower(property.Value) == "true"
+	case "proxy":
+		if err := parseProxy(target, property); err != nil {
+			return err
+		}
+	case "proxy_header":
+		if err := parseProxyHeader(target, property); err != nil {
+			return err
+		}
+	case "fault":
+		if err := parseFault(target, property); err != nil {
+			return err
+		}
+	case "variant":
+		if err := parseVariant(target, property); err != nil {
+			return err
+		}
+	case "step":
+		if err := parseStep(behavior, property); err != nil {
+			return err
+		}
+	case "sequence":
+		if err := parseSequence(behavior, property); err != nil {
+			return err
+		}
 	case "repeat":
 		if err := praseRepeat(behavior, property); err != nil {
 			return err
 		}
+	case "match.query":
+		if err := parseMatchQuery(behavior, property); err != nil {
+			return err
+		}
+	case "match.body":
+		behavior.RequestBody = Ptr(property.Value)
 	default:
 		if strings.HasPrefix(property.Key, "cookie") {
-			if err := parseCookie(behavior.ResponseBehavior, property); err != nil {
+			if err := parseCookie(target, property); err != nil {
 				return err
 			}
 			return nil
 		}
+		if strings.HasPrefix(property.Key, "sse.") || property.Key == "event" || strings.HasPrefix(property.Key, "event.") {
+			return parseEventStream(target, property)
+		}
+		if property.Key == "stream" || strings.HasPrefix(property.Key, "stream.") ||
+			property.Key == "chunk" || strings.HasPrefix(property.Key, "chunk.") {
+			return parseStream(target, property)
+		}
+		if strings.HasPrefix(property.Key, "grpc.") {
+			return parseGRPC(behavior, target, property)
+		}
+		if strings.HasPrefix(property.Key, "graphql.") {
+			return parseGraphQL(behavior, target, property)
+		}
+		if strings.HasPrefix(property.Key, "ws.") || property.Key == "on" || strings.HasPrefix(property.Key, "on.") {
+			return parseWebSocket(behavior, target, property)
+		}
 
-		return &MalformedPropertyError{
+		return &Warning{
 			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
 			Line:      property.Key + "=" + property.Value,
-			Details:   Ptr("Unknown property: " + property.Key),
+			Details:   "Unknown property " + property.Key + " is ignored",
 		}
 	}
 
@@ -120,6 +325,8 @@ func parseStatusCode(responseBehavior *model.ResponseBehavior, property ini.Prop
 	if err != nil || statusCode < 100 || statusCode > 599 {
 		return &MalformedPropertyError{
 			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
 			Line:      property.Key + "=" + property.Value,
 			Details:   Ptr("Invalid status code, must be an integer between 100 and 599"),
 		}
@@ -129,15 +336,22 @@ func parseStatusCode(responseBehavior *model.ResponseBehavior, property ini.Prop
 }
 
 func parseDelay(responseBehavior *model.ResponseBehavior, property ini.Property) error {
+	if strings.ContainsAny(property.Value, "(=") {
+		return parseDelayDistribution(responseBehavior, property)
+	}
+
 	delayDuration, err := time.ParseDuration(property.Value)
 	if err != nil || delayDuration < 0 {
 		return &MalformedPropertyError{
 			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
 			Line:      property.Key + "=" + property.Value,
 			Details:   Ptr("Invalid delay, must be a non-negative duration"),
 		}
 	}
 	responseBehavior.Delay = &delayDuration
+	responseBehavior.Latency = nil
 	return nil
 }
 
@@ -146,10 +360,70 @@ func parseHeaderAttribute(responseBehavior *model.ResponseBehavior, property ini
 		responseBehavior.Headers = make(map[string]string)
 	}
 
+	key, value, err := splitHeader(property)
+	if err != nil {
+		return err
+	}
+
+	responseBehavior.Headers[key] = value
+	return nil
+}
+
+// parseBodySchema sets the JSON Schema of the body, relative paths are resolved against the directory of the config file.
+func parseBodySchema(responseBehavior *model.ResponseBehavior, property ini.Property) error {
+	if property.Value == "" {
+		return &MalformedPropertyError{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   Ptr("Missing path of the JSON Schema"),
+		}
+	}
+	path := property.Value
+	if !filepath.IsAbs(path) && property.Source != "" {
+		path = filepath.Join(filepath.Dir(property.Source), path)
+	}
+	responseBehavior.BodySchema = &model.BodySchema{Ref: property.Value, Path: path}
+	return nil
+}
+
+func parseProxy(responseBehavior *model.ResponseBehavior, property ini.Property) error {
+	upstream, err := url.Parse(property.Value)
+	if err != nil || (upstream.Scheme != "http" && upstream.Scheme != "https") || upstream.Host == "" {
+		return &MalformedPropertyError{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   Ptr("Invalid proxy, must be an absolute http(s) URL"),
+		}
+	}
+	responseBehavior.Proxy = Ptr(property.Value)
+	return nil
+}
+
+func parseProxyHeader(responseBehavior *model.ResponseBehavior, property ini.Property) error {
+	if responseBehavior.ProxyHeaders == nil {
+		responseBehavior.ProxyHeaders = make(map[string]string)
+	}
+
+	key, value, err := splitHeader(property)
+	if err != nil {
+		return err
+	}
+
+	responseBehavior.ProxyHeaders[key] = value
+	return nil
+}
+
+func splitHeader(property ini.Property) (string, string, error) {
 	headerParts := strings.SplitN(property.Value, ":", twoParts)
 	if len(headerParts) != twoParts {
-		return &MalformedPropertyError{
+		return "", "", &MalformedPropertyError{
 			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
 			Line:      property.Key + "=" + property.Value,
 			Details:   Ptr("Invalid header format, expected 'Key: Value'"),
 		}
@@ -158,15 +432,16 @@ func parseHeaderAttribute(responseBehavior *model.ResponseBehavior, property ini
 	key := strings.TrimSpace(headerParts[0])
 	value := strings.TrimSpace(headerParts[1])
 	if key == "" || value == "" {
-		return &MalformedPropertyError{
+		return "", "", &MalformedPropertyError{
 			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
 			Line:      property.Key + "=" + property.Value,
 			Details:   Ptr("Header key and value cannot be empty"),
 		}
 	}
 
-	responseBehavior.Headers[key] = value
-	return nil
+	return key, value, nil
 }
 
 //nolint:funlen
@@ -181,6 +456,8 @@ func parseCookie(responseBehavior *model.ResponseBehavior, property ini.Property
 	case len(responseBehavior.Cookies) == 0:
 		return &MalformedPropertyError{
 			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
 			Line:      property.Key + "=" + property.Value,
 			Details:   Ptr("cookie.name must be set before other cookie properties"),
 		}
@@ -200,6 +477,8 @@ func parseCookie(responseBehavior *model.ResponseBehavior, property ini.Property
 		if err != nil {
 			return &MalformedPropertyError{
 				LineIndex: property.LineIndex,
+				Column:    property.Column,
+				Source:    property.Source,
 				Line:      property.Key + "=" + property.Value,
 				Details:   Ptr("Invalid cookie.expires duration"),
 			}
@@ -210,6 +489,8 @@ func parseCookie(responseBehavior *model.ResponseBehavior, property ini.Property
 		if err != nil {
 			return &MalformedPropertyError{
 				LineIndex: property.LineIndex,
+				Column:    property.Column,
+				Source:    property.Source,
 				Line:      property.Key + "=" + property.Value,
 				Details:   Ptr("Invalid cookie.raw_expires RFC3339 time"),
 			}
@@ -221,6 +502,8 @@ func parseCookie(responseBehavior *model.ResponseBehavior, property ini.Property
 		if err != nil {
 			return &MalformedPropertyError{
 				LineIndex: property.LineIndex,
+				Column:    property.Column,
+				Source:    property.Source,
 				Line:      property.Key + "=" + property.Value,
 				Details:   Ptr("Invalid cookie.max_age integer"),
 			}
@@ -241,6 +524,8 @@ func parseCookie(responseBehavior *model.ResponseBehavior, property ini.Property
 		default:
 			return &MalformedPropertyError{
 				LineIndex: property.LineIndex,
+				Column:    property.Column,
+				Source:    property.Source,
 				Line:      property.Key + "=" + property.Value,
 				Details:   Ptr("Invalid cookie.same_site value"),
 			}
@@ -250,6 +535,8 @@ func parseCookie(responseBehavior *model.ResponseBehavior, property ini.Property
 	default:
 		return &MalformedPropertyError{
 			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
 			Line:      property.Key + "=" + property.Value,
 			Details:   Ptr("Unknown cookie property: " + property.Value),
 		}
@@ -258,11 +545,215 @@ func parseCookie(responseBehavior *model.ResponseBehavior, property ini.Property
 	return nil
 }
 
+//nolint:cyclop
+func parseFault(responseBehavior *model.ResponseBehavior, property ini.Property) error {
+	malformed := func(details string) error {
+		return &MalformedPropertyError{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   Ptr(details),
+		}
+	}
+
+	fields := strings.Fields(property.Value)
+	if len(fields) == 0 || len(fields) > twoParts {
+		return malformed("Invalid fault format, expected '<mode>[=<value>] [probability]'")
+	}
+
+	modeParts := strings.SplitN(fields[0], "=", twoParts)
+	mode, match := model.FaultModeFromString(modeParts[0])
+	if !match {
+		return malformed("Unknown fault mode: " + modeParts[0])
+	}
+	fault := &model.Fault{Mode: mode, Probability: 1}
+
+	switch mode {
+	case model.FaultTruncate, model.FaultSlowBody:
+		if len(modeParts) != twoParts {
+			return malformed("Fault mode " + string(mode) + " requires a value")
+		}
+		value, err := strconv.Atoi(modeParts[1])
+		if err != nil || value <= 0 {
+			return malformed("Invalid fault value, must be a positive integer")
+		}
+		fault.Value = value
+	case model.FaultReset, model.FaultEmpty, model.FaultMalformedHeaders:
+		if len(modeParts) == twoParts {
+			return malformed("Fault mode " + string(mode) + " does not take a value")
+		}
+	}
+
+	if len(fields) == twoParts {
+		raw := fields[1]
+		divisor := 1.0
+		if strings.HasSuffix(raw, "%") {
+			raw = strings.TrimSuffix(raw, "%")
+			divisor = 100
+		}
+		probability, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(probability) || probability <= 0 || probability/divisor > 1 {
+			return malformed("Invalid fault probability, must be within (0, 1] or (0%, 100%]")
+		}
+		fault.Probability = probability / divisor
+	}
+
+	total := fault.Probability
+	for _, existing := range responseBehavior.Faults {
+		total += existing.Probability
+	}
+	if total > 1+probabilityTolerance {
+		return malformed("Sum of fault probabilities cannot exceed 1")
+	}
+
+	responseBehavior.Faults = append(responseBehavior.Faults, fault)
+	return nil
+}
+
+func parseVariant(responseBehavior *model.ResponseBehavior, property ini.Property) error {
+	weight, err := strconv.Atoi(strings.TrimSuffix(property.Value, "%"))
+	if err != nil || weight <= 0 {
+		return &MalformedPropertyError{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   Ptr("Invalid variant weight, must be a positive integer"),
+		}
+	}
+	responseBehavior.Variants = append(responseBehavior.Variants, &model.Variant{
+		ResponseBehavior: &model.ResponseBehavior{},
+		Weight:           uint(weight),
+	})
+	return nil
+}
+
+func parseStep(behavior *model.Behavior, property ini.Property) error {
+	count, err := strconv.Atoi(property.Value)
+	if err != nil || count <= 0 {
+		return &MalformedPropertyError{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   Ptr("Invalid step count, must be a positive integer"),
+		}
+	}
+	behavior.Steps = append(behavior.Steps, &model.Step{
+		ResponseBehavior: &model.ResponseBehavior{},
+		Count:            uint(count),
+	})
+	return nil
+}
+
+func parseSequence(behavior *model.Behavior, property ini.Property) error {
+	switch strings.ToLower(property.Value) {
+	case "loop":
+		behavior.Loop = true
+	case "stick":
+		behavior.Loop = false
+	default:
+		return &MalformedPropertyError{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   Ptr("Invalid sequence value, must be 'loop' or 'stick'"),
+		}
+	}
+	return nil
+}
+
+// inheritSteps fills everything a step or variant does not define from its enclosing response behavior.
+// Variants are not inherited, a step only serves the variants declared within it.
+func inheritSteps(behavior *model.Behavior) {
+	inheritVariants(behavior.ResponseBehavior)
+	for _, step := range behavior.Steps {
+		inherit(behavior.ResponseBehavior, step.ResponseBehavior)
+		inheritVariants(step.ResponseBehavior)
+	}
+}
+
+func inheritVariants(responseBehavior *model.ResponseBehavior) {
+	for _, variant := range responseBehavior.Variants {
+		inherit(responseBehavior, variant.ResponseBehavior)
+	}
+}
+
+//nolint:cyclop
+func inherit(parent, child *model.ResponseBehavior) {
+	if child.Delay == nil && child.Latency == nil {
+		child.Delay = parent.Delay
+		child.Latency = parent.Latency
+	}
+	if child.ChunkDelay == nil {
+		child.ChunkDelay = parent.ChunkDelay
+	}
+	if child.ChunkSize == nil {
+		child.ChunkSize = parent.ChunkSize
+	}
+	if child.Bandwidth == nil {
+		child.Bandwidth = parent.Bandwidth
+	}
+	if child.StatusCode == nil {
+		child.StatusCode = parent.StatusCode
+	}
+	if child.Body == nil {
+		child.Body = parent.Body
+	}
+	if child.BodySchema == nil {
+		child.BodySchema = parent.BodySchema
+	}
+	if child.Redirect == nil {
+		child.Redirect = parent.Redirect
+	}
+	if child.Proxy == nil {
+		child.Proxy = parent.Proxy
+	}
+	if child.Cookies == nil {
+		child.Cookies = parent.Cookies
+	}
+	if child.Faults == nil {
+		child.Faults = parent.Faults
+	}
+	if child.GRPC == nil {
+		child.GRPC = parent.GRPC
+	}
+	if child.GraphQL == nil {
+		child.GraphQL = parent.GraphQL
+	}
+	if child.WebSocket == nil {
+		child.WebSocket = parent.WebSocket
+	}
+	if child.Stream == nil {
+		child.Stream = parent.Stream
+	}
+	if child.EventStream == nil {
+		child.EventStream = parent.EventStream
+	}
+	child.SSE = child.SSE || parent.SSE
+	child.Headers = mergeHeaders(parent.Headers, child.Headers)
+	child.ProxyHeaders = mergeHeaders(parent.ProxyHeaders, child.ProxyHeaders)
+}
+
+func mergeHeaders(base, override map[string]string) map[string]string {
+	if base == nil {
+		return override
+	}
+	merged := make(map[string]string, len(base)+len(override))
+	maps.Copy(merged, base)
+	maps.Copy(merged, override)
+	return merged
+}
+
 func praseRepeat(behavior *model.Behavior, property ini.Property) error {
 	repeat, err := strconv.Atoi(property.Value)
 	if err != nil || repeat < 0 {
 		return &MalformedPropertyError{
 			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
 			Line:      property.Key + "=" + property.Value,
 			Details:   Ptr("Invalid repeat value, must be a non-negative integer"),
 		}
@@ -271,3 +762,18 @@ func praseRepeat(behavior *model.Behavior, property ini.Property) error {
 	behavior.Repeat = &repeatUint
 	return nil
 }
+
+func parseMatchQuery(behavior *model.Behavior, property ini.Property) error {
+	query, err := url.ParseQuery(strings.TrimPrefix(property.Value, "?"))
+	if err != nil {
+		return &MalformedPropertyError{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   Ptr("Invalid match.query, expected an URL encoded query like 'page=2&sort=asc'"),
+		}
+	}
+	behavior.Query = query
+	return nil
+}
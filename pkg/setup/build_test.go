@@ -2,10 +2,15 @@ package setup
 
 import (
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -23,6 +28,109 @@ func TestBuild_DefaultAndCustomBehavior(t *testing.T) {
 	assert.Equal(t, "bar", *bs.Behaviors[0].ResponseBehavior.Body)
 }
 
+func TestBuild_MergedFiles(t *testing.T) {
+	// Sections of two files, each starting with its own default section.
+	sections := []ini.Section{
+		{Name: "default", Properties: []ini.Property{
+			{Key: "status_code", Value: "404"},
+			{Key: "body", Value: "not found"},
+		}},
+		{Name: "GET /users", LineIndex: 1, Properties: []ini.Property{{Key: "body", Value: "users"}}},
+		{Name: "default", Properties: []ini.Property{{Key: "status_code", Value: "418"}}},
+		{Name: "GET /users", LineIndex: 1, Properties: []ini.Property{{Key: "body", Value: "other"}}},
+		{Name: "GET /orders", LineIndex: 2, Properties: []ini.Property{{Key: "body", Value: "orders"}}},
+	}
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	require.NotNil(t, bs.DefaultBehavior)
+	assert.Equal(t, uint16(418), *bs.DefaultBehavior.StatusCode)
+	assert.Equal(t, "not found", *bs.DefaultBehavior.Body)
+	require.Len(t, bs.Behaviors, 3)
+	assert.Equal(t, "users", *bs.Behaviors[0].ResponseBehavior.Body)
+	assert.Equal(t, "other", *bs.Behaviors[1].ResponseBehavior.Body)
+	assert.Equal(t, "orders", *bs.Behaviors[2].ResponseBehavior.Body)
+}
+
+func TestBuild_Templates(t *testing.T) {
+	raw := `
+header = Content-Type: application/json
+header = X-Served-By: servmock
+inherit_headers = true
+
+[template json_ok]
+status_code = 200
+delay = 10ms
+cookie.name = session
+cookie.value = abc
+
+[template authenticated : json_ok]
+header = WWW-Authenticate: Bearer
+
+[GET /admin/users : authenticated]
+status_code = 201
+header = Content-Type: application/problem+json
+
+[GET /health]
+body = ok
+`
+	sections, err := ini.Parse(strings.NewReader(raw), true)
+	require.NoError(t, err)
+
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	require.Len(t, bs.Behaviors, 2)
+	assert.Equal(t, map[string]string{"Content-Type": "application/json", "X-Served-By": "servmock"}, bs.DefaultBehavior.Headers)
+
+	admin := bs.Behaviors[0]
+	assert.Equal(t, "/admin/users", admin.URL)
+	assert.Equal(t, uint16(201), *admin.StatusCode)
+	assert.Equal(t, 10*time.Millisecond, *admin.Delay)
+	require.Len(t, admin.Cookies, 1)
+	assert.Equal(t, "session", admin.Cookies[0].Name)
+	assert.Equal(t, map[string]string{
+		"Content-Type":     "application/problem+json",
+		"X-Served-By":      "servmock",
+		"WWW-Authenticate": "Bearer",
+	}, admin.Headers)
+
+	health := bs.Behaviors[1]
+	assert.Nil(t, health.StatusCode)
+	assert.Equal(t, map[string]string{"Content-Type": "application/json", "X-Served-By": "servmock"}, health.Headers)
+}
+
+func TestBuild_TemplatesInvalid(t *testing.T) {
+	tests := map[string]struct {
+		raw string
+		err string
+	}{
+		"unknown template": {
+			raw: "[GET /users : missing]\n",
+			err: "Malformed behavior header at line 1, column 1: GET /users : missing - Unknown template: missing",
+		},
+		"template cycle": {
+			raw: "[template a : b]\n[template b : a]\n[GET /users : a]\n",
+			err: "Malformed behavior header at line 1, column 1: template a : b - Template cycle: a -> b -> a",
+		},
+		"template name": {
+			raw: "[template json ok]\n",
+			err: "Malformed behavior header at line 1, column 1: template json ok - Template name must be a single word",
+		},
+		"inherit headers": {
+			raw: "inherit_headers = maybe\n",
+			err: "Malformed property at line 1, column 1: inherit_headers=maybe - Invalid inherit_headers, must be true or false",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sections, err := ini.Parse(strings.NewReader(test.raw), true)
+			require.NoError(t, err)
+
+			_, err = Build(sections)
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
+
 func TestBuild_MalformedBehaviorHeaderError(t *testing.T) {
 	sections := []ini.Section{
 		{Name: "GET", LineIndex: 2, Properties: []ini.Property{}},
@@ -30,17 +138,120 @@ func TestBuild_MalformedBehaviorHeaderError(t *testing.T) {
 	bs, err := Build(sections)
 	assert.Nil(t, bs)
 	require.Error(t, err)
-	assert.IsType(t, &MalformedBehaviorHeaderError{}, err)
+	var headerErr *MalformedBehaviorHeaderError
+	assert.ErrorAs(t, err, &headerErr)
 }
 
 func TestBuild_MalformedPropertyError(t *testing.T) {
 	sections := []ini.Section{
-		{Name: "GET /bar", LineIndex: 3, Properties: []ini.Property{{Key: "unknown", Value: "val", LineIndex: 3}}},
+		{Name: "GET /bar", LineIndex: 3, Properties: []ini.Property{{Key: "repeat", Value: "val", LineIndex: 3}}},
 	}
 	bs, err := Build(sections)
 	assert.Nil(t, bs)
 	require.Error(t, err)
-	assert.IsType(t, &MalformedPropertyError{}, err)
+	var propertyErr *MalformedPropertyError
+	assert.ErrorAs(t, err, &propertyErr)
+}
+
+func TestBuild_ErrorSource(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "GET /bar", LineIndex: 3, Source: "main.ini", Properties: []ini.Property{
+			{Key: "repeat", Value: "val", LineIndex: 7, Column: 3, Source: "common/auth.ini"},
+		}},
+	}
+	_, err := Build(sections)
+	require.EqualError(t, err,
+		"Malformed property at line 7, column 3 of common/auth.ini: repeat=val - Invalid repeat value, must be a non-negative integer")
+
+	sections = []ini.Section{{Name: "GET", LineIndex: 2, Source: "main.ini"}}
+	_, err = Build(sections)
+	require.EqualError(t, err, "Malformed behavior header at line 2 of main.ini: GET")
+}
+
+func TestBuild_CollectsErrors(t *testing.T) {
+	raw := `
+status_code = 999
+
+[GET]
+body = never checked
+
+[GET /users : missing]
+
+[GET /orders]
+delay = soon
+repeat = -1
+`
+	sections, err := ini.Parse(strings.NewReader(raw), true)
+	require.NoError(t, err)
+
+	_, err = Build(sections)
+	require.EqualError(t, err, strings.Join([]string{
+		"Malformed behavior header at line 7, column 1: GET /users : missing - Unknown template: missing",
+		"Malformed property at line 2, column 1: status_code=999 - Invalid status code, must be an integer between 100 and 599",
+		"Malformed behavior header at line 4, column 1: GET",
+		"Malformed property at line 10, column 1: delay=soon - Invalid delay, must be a non-negative duration",
+		"Malformed property at line 11, column 1: repeat=-1 - Invalid repeat value, must be a non-negative integer",
+	}, "\n"))
+}
+
+func TestBuild_Warnings(t *testing.T) {
+	raw := `
+colour = blue
+
+[GET /users]
+body = first
+
+[GET /users]
+body = shadowed
+
+[GET /orders]
+repeat = 2
+
+[GET /orders]
+repeat = 0
+
+[GRAPHQL /graphql]
+graphql.operation = GetUser
+
+[GRAPHQL /graphql]
+graphql.operation = GetOrder
+
+[GET /search]
+redirect = /find
+
+[GET /moved]
+status_code = 301
+redirect = /new
+
+[GET /pets/{id}]
+body = pet
+
+[GET /pets/me]
+body = shadowed
+
+[GET /pets/{id}/toys]
+body = toys
+`
+	sections, err := ini.Parse(strings.NewReader(raw), true)
+	require.NoError(t, err)
+
+	var warnings []string
+	bs, err := Build(sections, WithWarningHandler(func(err error) {
+		warnings = append(warnings, err.Error())
+	}))
+	require.NoError(t, err)
+	assert.Len(t, bs.Behaviors, 11)
+	assert.Equal(t, model.Origin{Section: "GET /users", Line: 7}, bs.Behaviors[1].Origin)
+	assert.Equal(t, []string{
+		"Warning at line 2, column 1: colour=blue - Unknown property colour is ignored",
+		"Warning at line 7, column 1: [GET /users] - Behavior is never served, it is shadowed by [GET /users] at line 4, column 1",
+		"Warning at line 14, column 1: repeat=0 - Repeat of 0 never runs out, the behavior is served indefinitely",
+		"Warning at line 23, column 1: redirect=/find - Redirect is served with status code 200 instead of a 3xx status code",
+		"Warning at line 32, column 1: [GET /pets/me] - Behavior is never served, it is shadowed by [GET /pets/{id}] at line 29, column 1",
+	}, warnings)
+
+	_, err = Build(sections)
+	require.NoError(t, err)
 }
 
 func TestBuild_StatusCodeValidation(t *testing.T) {
@@ -310,3 +521,940 @@ func TestBuild_InvalidUrl(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "URL cannot be empty")
 }
+
+func TestBuild_ProxyProperty(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "default", Properties: []ini.Property{
+			{Key: "proxy", Value: "http://upstream:8080", LineIndex: 1},
+			{Key: "proxy_header", Value: "Host: example.com", LineIndex: 2},
+		}},
+		{Name: "GET /proxy", LineIndex: 3,
+			Properties: []ini.Property{{Key: "proxy", Value: "https://other", LineIndex: 4}}},
+	}
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	assert.Equal(t, "http://upstream:8080", *bs.DefaultBehavior.Proxy)
+	assert.Equal(t, "example.com", bs.DefaultBehavior.ProxyHeaders["Host"])
+	assert.Equal(t, "https://other", *bs.Behaviors[0].ResponseBehavior.Proxy)
+}
+
+func TestBuild_ProxyPropertyInvalid(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "default", Properties: []ini.Property{{Key: "proxy", Value: "upstream:8080", LineIndex: 1}}},
+	}
+	bs, err := Build(sections)
+	assert.Nil(t, bs)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid proxy")
+}
+
+func TestBuild_ProxyHeaderInvalid(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "default", Properties: []ini.Property{{Key: "proxy_header", Value: "X-Test", LineIndex: 1}}},
+	}
+	bs, err := Build(sections)
+	assert.Nil(t, bs)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid header format")
+}
+
+func TestBuild_FaultProperty(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "GET /fault", LineIndex: 23, Properties: []ini.Property{
+			{Key: "fault", Value: "reset 0.1", LineIndex: 24},
+			{Key: "fault", Value: "truncate=64 25%", LineIndex: 25},
+			{Key: "fault", Value: "slow_body=16", LineIndex: 26},
+		}},
+	}
+	_, err := Build(sections)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Sum of fault probabilities cannot exceed 1")
+
+	sections[0].Properties = sections[0].Properties[:2]
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	faults := bs.Behaviors[0].ResponseBehavior.Faults
+	assert.Len(t, faults, 2)
+	assert.Equal(t, model.FaultReset, faults[0].Mode)
+	assert.InDelta(t, 0.1, faults[0].Probability, 0.0001)
+	assert.Equal(t, model.FaultTruncate, faults[1].Mode)
+	assert.Equal(t, 64, faults[1].Value)
+	assert.InDelta(t, 0.25, faults[1].Probability, 0.0001)
+}
+
+func TestBuild_FaultPropertyInvalid(t *testing.T) {
+	for value, msg := range map[string]string{
+		"explode":           "Unknown fault mode",
+		"truncate":          "requires a value",
+		"truncate=-1":       "Invalid fault value",
+		"reset=1":           "does not take a value",
+		"empty 1.5":         "Invalid fault probability",
+		"empty 0":           "Invalid fault probability",
+		"empty NaN":         "Invalid fault probability",
+		"empty NaN%":        "Invalid fault probability",
+		"empty 10% too":     "Invalid fault format",
+		"malformed_headers": "",
+	} {
+		sections := []ini.Section{
+			{Name: "GET /fault", LineIndex: 27, Properties: []ini.Property{{Key: "fault", Value: value, LineIndex: 28}}},
+		}
+		_, err := Build(sections)
+		if msg == "" {
+			require.NoError(t, err, value)
+			continue
+		}
+		require.Error(t, err, value)
+		assert.Contains(t, err.Error(), msg, value)
+	}
+}
+
+func TestBuild_VariantProperty(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "GET /variant", LineIndex: 29, Properties: []ini.Property{
+			{Key: "header", Value: "Content-Type: application/json", LineIndex: 30},
+			{Key: "body", Value: "{}", LineIndex: 31},
+			{Key: "variant", Value: "90%", LineIndex: 32},
+			{Key: "status_code", Value: "200", LineIndex: 33},
+			{Key: "variant", Value: "10", LineIndex: 34},
+			{Key: "status_code", Value: "500", LineIndex: 35},
+			{Key: "body", Value: "{\"error\":true}", LineIndex: 36},
+			{Key: "repeat", Value: "3", LineIndex: 37},
+		}},
+	}
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	behavior := bs.Behaviors[0]
+	assert.Equal(t, uint(3), *behavior.Repeat)
+	assert.Nil(t, behavior.StatusCode)
+	assert.Len(t, behavior.Variants, 2)
+	ok := behavior.Variants[0]
+	assert.Equal(t, uint(90), ok.Weight)
+	assert.Equal(t, uint16(200), *ok.StatusCode)
+	assert.Equal(t, "{}", *ok.Body)
+	assert.Equal(t, "application/json", ok.Headers["Content-Type"])
+	fail := behavior.Variants[1]
+	assert.Equal(t, uint(10), fail.Weight)
+	assert.Equal(t, uint16(500), *fail.StatusCode)
+	assert.Equal(t, "{\"error\":true}", *fail.Body)
+	assert.Equal(t, "application/json", fail.Headers["Content-Type"])
+}
+
+func TestBuild_VariantPropertyInvalid(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "GET /variant", LineIndex: 38, Properties: []ini.Property{{Key: "variant", Value: "0", LineIndex: 39}}},
+	}
+	bs, err := Build(sections)
+	assert.Nil(t, bs)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid variant weight")
+}
+
+func TestBuild_StepProperty(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "GET /job/1", LineIndex: 40, Properties: []ini.Property{
+			{Key: "header", Value: "Content-Type: application/json", LineIndex: 41},
+			{Key: "sequence", Value: "loop", LineIndex: 42},
+			{Key: "step", Value: "2", LineIndex: 43},
+			{Key: "status_code", Value: "202", LineIndex: 44},
+			{Key: "step", Value: "1", LineIndex: 45},
+			{Key: "body", Value: "done", LineIndex: 46},
+			{Key: "variant", Value: "1", LineIndex: 47},
+			{Key: "variant", Value: "1", LineIndex: 48},
+			{Key: "status_code", Value: "500", LineIndex: 49},
+		}},
+	}
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	behavior := bs.Behaviors[0]
+	assert.True(t, behavior.Loop)
+	assert.Len(t, behavior.Steps, 2)
+	assert.Equal(t, uint(2), behavior.Steps[0].Count)
+	assert.Equal(t, uint16(202), *behavior.Steps[0].StatusCode)
+	assert.Equal(t, "application/json", behavior.Steps[0].Headers["Content-Type"])
+	assert.Equal(t, "done", *behavior.Steps[1].Body)
+	assert.Len(t, behavior.Steps[1].Variants, 2)
+	assert.Equal(t, "done", *behavior.Steps[1].Variants[1].Body)
+	assert.Equal(t, uint16(500), *behavior.Steps[1].Variants[1].StatusCode)
+}
+
+func TestBuild_StepsDoNotInheritVariants(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "GET /job", LineIndex: 1, Properties: []ini.Property{
+			{Key: "variant", Value: "1", LineIndex: 2},
+			{Key: "body", Value: "parent-variant", LineIndex: 3},
+			{Key: "step", Value: "1", LineIndex: 4},
+			{Key: "body", Value: "step-one", LineIndex: 5},
+		}},
+	}
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	behavior := bs.Behaviors[0]
+	assert.Len(t, behavior.Variants, 1)
+	require.Len(t, behavior.Steps, 1)
+	assert.Empty(t, behavior.Steps[0].Variants)
+
+	var warnings []string
+	_, err = Build(sections, WithWarningHandler(func(err error) {
+		warnings = append(warnings, err.Error())
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Warning at line 2: variant=1 - Variant is never served, steps only serve the variants declared within them",
+	}, warnings)
+}
+
+func TestBuild_StepPropertyInvalid(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "GET /job/1", LineIndex: 50, Properties: []ini.Property{{Key: "step", Value: "zero", LineIndex: 51}}},
+	}
+	_, err := Build(sections)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid step count")
+
+	sections[0].Properties = []ini.Property{{Key: "sequence", Value: "forever", LineIndex: 51}}
+	_, err = Build(sections)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid sequence value")
+}
+
+func TestBuild_DelayDistribution(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "GET /uniform", LineIndex: 52,
+			Properties: []ini.Property{{Key: "delay", Value: "uniform(100ms, 500ms)", LineIndex: 53}}},
+		{Name: "GET /normal", LineIndex: 54,
+			Properties: []ini.Property{{Key: "delay", Value: "normal(200ms,50ms)", LineIndex: 55}}},
+		{Name: "GET /lognormal", LineIndex: 56,
+			Properties: []ini.Property{{Key: "delay", Value: "lognormal(200ms, 1s)", LineIndex: 57}}},
+		{Name: "GET /percentiles", LineIndex: 58,
+			Properties: []ini.Property{{Key: "delay", Value: "p99=800ms, p50=20ms", LineIndex: 59}}},
+	}
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	uniform := bs.Behaviors[0].Latency
+	assert.Equal(t, model.LatencyUniform, uniform.Distribution)
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 500 * time.Millisecond}, uniform.Params)
+	assert.Equal(t, model.LatencyNormal, bs.Behaviors[1].Latency.Distribution)
+	assert.Equal(t, model.LatencyLogNormal, bs.Behaviors[2].Latency.Distribution)
+	percentiles := bs.Behaviors[3].Latency
+	assert.Equal(t, model.LatencyPercentiles, percentiles.Distribution)
+	assert.Equal(t, []model.Percentile{
+		{Percentile: 50, Delay: 20 * time.Millisecond},
+		{Percentile: 99, Delay: 800 * time.Millisecond},
+	}, percentiles.Percentiles)
+}
+
+func TestBuild_DelayOverride(t *testing.T) {
+	raw := `
+[template slow]
+delay = uniform(100ms, 500ms)
+
+[GET /fixed : slow]
+delay = 200ms
+
+[GET /distribution]
+delay = 50ms
+delay = p50=20ms, p99=800ms
+
+[GET /redefined]
+delay = normal(200ms, 50ms)
+delay = 30ms
+`
+	sections, err := ini.Parse(strings.NewReader(raw), true)
+	require.NoError(t, err)
+
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	require.Len(t, bs.Behaviors, 3)
+
+	fixed := bs.Behaviors[0]
+	assert.Equal(t, 200*time.Millisecond, *fixed.Delay)
+	assert.Nil(t, fixed.Latency, "a fixed delay replaces the distribution of the template")
+
+	distribution := bs.Behaviors[1]
+	assert.Nil(t, distribution.Delay, "a distribution replaces an earlier fixed delay")
+	assert.Equal(t, model.LatencyPercentiles, distribution.Latency.Distribution)
+
+	redefined := bs.Behaviors[2]
+	assert.Equal(t, 30*time.Millisecond, *redefined.Delay)
+	assert.Nil(t, redefined.Latency)
+}
+
+func TestBuild_DelayDistributionInvalid(t *testing.T) {
+	for value, msg := range map[string]string{
+		"gamma(1s, 2s)":          "Unknown delay distribution",
+		"uniform(1s)":            "requires exactly two durations",
+		"uniform(2s, 1s)":        "min cannot be greater than max",
+		"normal(1s, -1s)":        "Invalid delay distribution parameter",
+		"lognormal(0s, 1s)":      "mean must be positive",
+		"uniform(1s, 2s":         "Invalid delay",
+		"p50=1s,x=2s":            "Invalid delay percentile",
+		"p101=1s":                "must be between p0 and p100",
+		"p50=fast":               "Invalid delay percentile duration",
+		"p50=500ms,p99=100ms":    "delays must not decrease",
+		"p0=1ms,p50=2ms,p100=3s": "",
+	} {
+		sections := []ini.Section{
+			{Name: "GET /delay", LineIndex: 60, Properties: []ini.Property{{Key: "delay", Value: value, LineIndex: 61}}},
+		}
+		_, err := Build(sections)
+		if msg == "" {
+			require.NoError(t, err, value)
+			continue
+		}
+		require.Error(t, err, value)
+		assert.Contains(t, err.Error(), msg, value)
+	}
+}
+
+func TestBuild_ThrottleProperties(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "GET /slow", LineIndex: 62, Properties: []ini.Property{
+			{Key: "bandwidth", Value: "64KiB/s", LineIndex: 63},
+			{Key: "chunk_size", Value: "1.5kb", LineIndex: 64},
+			{Key: "chunk_delay", Value: "100ms", LineIndex: 65},
+		}},
+	}
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	behavior := bs.Behaviors[0]
+	assert.Equal(t, uint64(64*1024), *behavior.Bandwidth)
+	assert.Equal(t, uint64(1500), *behavior.ChunkSize)
+	assert.Equal(t, 100*time.Millisecond, *behavior.ChunkDelay)
+}
+
+func TestBuild_ThrottlePropertiesInvalid(t *testing.T) {
+	for key, msg := range map[string]string{
+		"bandwidth":   "Invalid bandwidth",
+		"chunk_size":  "Invalid chunk size",
+		"chunk_delay": "Invalid chunk delay",
+	} {
+		sections := []ini.Section{
+			{Name: "GET /slow", LineIndex: 66, Properties: []ini.Property{{Key: key, Value: "-1", LineIndex: 67}}},
+		}
+		_, err := Build(sections)
+		require.Error(t, err, key)
+		assert.Contains(t, err.Error(), msg, key)
+	}
+}
+
+func TestBuild_EventStreamProperties(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "GET /events", LineIndex: 68, Properties: []ini.Property{
+			{Key: "sse.interval", Value: "500ms", LineIndex: 69},
+			{Key: "sse.keep_alive", Value: "15s", LineIndex: 70},
+			{Key: "sse.loop", Value: "true", LineIndex: 71},
+			{Key: "sse.hold", Value: "false", LineIndex: 72},
+			{Key: "event", Value: "token", LineIndex: 73},
+			{Key: "event.id", Value: "1", LineIndex: 74},
+			{Key: "event.data", Value: "first line", LineIndex: 75},
+			{Key: "event.data", Value: "second line", LineIndex: 76},
+			{Key: "event.retry", Value: "3000", LineIndex: 77},
+			{Key: "event.delay", Value: "1s", LineIndex: 78},
+			{Key: "event", Value: "", LineIndex: 79},
+			{Key: "event.data", Value: "[DONE]", LineIndex: 80},
+		}},
+	}
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	behavior := bs.Behaviors[0]
+	assert.True(t, behavior.SSE)
+	stream := behavior.EventStream
+	assert.Equal(t, 500*time.Millisecond, *stream.Interval)
+	assert.Equal(t, 15*time.Second, *stream.KeepAlive)
+	assert.True(t, stream.Loop)
+	assert.False(t, stream.Hold)
+	assert.Len(t, stream.Events, 2)
+	assert.Equal(t, "token", stream.Events[0].Type)
+	assert.Equal(t, "1", *stream.Events[0].ID)
+	assert.Equal(t, []string{"first line", "second line"}, stream.Events[0].Data)
+	assert.Equal(t, uint(3000), *stream.Events[0].Retry)
+	assert.Equal(t, time.Second, *stream.Events[0].Delay)
+	assert.Empty(t, stream.Events[1].Type)
+	assert.Equal(t, []string{"[DONE]"}, stream.Events[1].Data)
+}
+
+func TestBuild_EventStreamPropertiesInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		properties []ini.Property
+		msg        string
+	}{
+		{[]ini.Property{{Key: "event.data", Value: "x"}}, "event must be set before other event properties"},
+		{[]ini.Property{{Key: "sse.interval", Value: "soon"}}, "Invalid sse.interval"},
+		{[]ini.Property{{Key: "sse.keep_alive", Value: "0s"}}, "Invalid sse.keep_alive"},
+		{[]ini.Property{{Key: "event", Value: "x"}, {Key: "event.retry", Value: "-1"}}, "Invalid event.retry"},
+		{[]ini.Property{{Key: "event", Value: "x"}, {Key: "event.unknown", Value: "1"}}, "Unknown event stream property"},
+		{[]ini.Property{{Key: "sse.unknown", Value: "1"}}, "Unknown event stream property"},
+	} {
+		sections := []ini.Section{{Name: "GET /events", LineIndex: 81, Properties: tc.properties}}
+		_, err := Build(sections)
+		require.Error(t, err, tc.msg)
+		assert.Contains(t, err.Error(), tc.msg)
+	}
+}
+
+func TestBuild_WebSocketProperties(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "WS /socket", LineIndex: 82, Properties: []ini.Property{
+			{Key: "ws.send", Value: `{"type":"hello"}`, LineIndex: 83},
+			{Key: "ws.ping", Value: "30s", LineIndex: 84},
+			{Key: "ws.close", Value: "1001 going away", LineIndex: 85},
+			{Key: "ws.close_after", Value: "1m", LineIndex: 86},
+			{Key: "on", Value: `^subscribe:(\w+)$`, LineIndex: 87},
+			{Key: "on.send", Value: `{"subscribed":"${1}"}`, LineIndex: 88},
+			{Key: "on", Value: `^bye$`, LineIndex: 89},
+			{Key: "on.close", Value: "1000", LineIndex: 90},
+		}},
+		{Name: "WS /empty", LineIndex: 91},
+	}
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	script := bs.Behaviors[0].WebSocket
+	assert.Equal(t, model.MethodWS, bs.Behaviors[0].Method)
+	assert.Equal(t, []string{`{"type":"hello"}`}, script.OnConnect)
+	assert.Equal(t, 30*time.Second, *script.Ping)
+	assert.Equal(t, &model.WebSocketClose{Code: 1001, Reason: "going away"}, script.Close)
+	assert.Equal(t, time.Minute, *script.CloseAfter)
+	assert.Len(t, script.Replies, 2)
+	assert.True(t, script.Replies[0].Pattern.MatchString("subscribe:prices"))
+	assert.Equal(t, []string{`{"subscribed":"${1}"}`}, script.Replies[0].Frames)
+	assert.Equal(t, &model.WebSocketClose{Code: 1000}, script.Replies[1].Close)
+	assert.NotNil(t, bs.Behaviors[1].WebSocket)
+}
+
+func TestBuild_WebSocketPropertiesInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		properties []ini.Property
+		msg        string
+	}{
+		{"GET /socket", []ini.Property{{Key: "ws.send", Value: "x"}}, "WebSocket properties require a WS behavior"},
+		{"WS /socket", []ini.Property{{Key: "on.send", Value: "x"}}, "on must be set before other on properties"},
+		{"WS /socket", []ini.Property{{Key: "on", Value: "("}}, "Invalid on pattern"},
+		{"WS /socket", []ini.Property{{Key: "ws.ping", Value: "0s"}}, "Invalid ws.ping"},
+		{"WS /socket", []ini.Property{{Key: "ws.close", Value: "999"}}, "Invalid ws.close"},
+		{"WS /socket", []ini.Property{{Key: "ws.unknown", Value: "1"}}, "Unknown WebSocket property"},
+	} {
+		sections := []ini.Section{{Name: tc.name, LineIndex: 92, Properties: tc.properties}}
+		_, err := Build(sections)
+		require.Error(t, err, tc.msg)
+		assert.Contains(t, err.Error(), tc.msg)
+	}
+}
+
+func TestBuild_StreamProperties(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "GET /stream", LineIndex: 93, Properties: []ini.Property{
+			{Key: "stream", Value: "multipart", LineIndex: 94},
+			{Key: "stream.boundary", Value: "frame", LineIndex: 95},
+			{Key: "chunk", Value: "one", LineIndex: 96},
+			{Key: "chunk.delay", Value: "1s", LineIndex: 97},
+			{Key: "chunk.content_type", Value: "image/jpeg", LineIndex: 98},
+			{Key: "chunk", Value: "two", LineIndex: 99},
+		}},
+		{Name: "GET /events", LineIndex: 100, Properties: []ini.Property{
+			{Key: "stream", Value: "sse", LineIndex: 101},
+		}},
+	}
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	stream := bs.Behaviors[0].Stream
+	assert.Equal(t, model.StreamMultipart, stream.Mode)
+	assert.Equal(t, "frame", stream.Boundary)
+	assert.Len(t, stream.Chunks, 2)
+	assert.Equal(t, "one", stream.Chunks[0].Data)
+	assert.Equal(t, time.Second, *stream.Chunks[0].Delay)
+	assert.Equal(t, "image/jpeg", stream.Chunks[0].ContentType)
+	assert.Equal(t, "two", stream.Chunks[1].Data)
+	assert.False(t, bs.Behaviors[0].SSE)
+	assert.True(t, bs.Behaviors[1].SSE)
+}
+
+func TestBuild_StreamPropertiesInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		properties []ini.Property
+		msg        string
+	}{
+		{[]ini.Property{{Key: "stream", Value: "carrier-pigeon"}}, "Unknown stream mode"},
+		{[]ini.Property{{Key: "chunk.delay", Value: "1s"}}, "chunk must be set before other chunk properties"},
+		{[]ini.Property{{Key: "chunk", Value: "x"}, {Key: "chunk.delay", Value: "-1s"}}, "Invalid chunk.delay"},
+		{[]ini.Property{{Key: "stream", Value: "ndjson"}, {Key: "chunk", Value: "{"}}, "Invalid NDJSON chunk"},
+		{[]ini.Property{{Key: "chunk", Value: "nope"}, {Key: "stream", Value: "ndjson"}}, "Invalid NDJSON chunk"},
+		{[]ini.Property{{Key: "stream.unknown", Value: "x"}}, "Unknown stream property"},
+	} {
+		sections := []ini.Section{{Name: "GET /stream", LineIndex: 102, Properties: tc.properties}}
+		_, err := Build(sections)
+		require.Error(t, err, tc.msg)
+		assert.Contains(t, err.Error(), tc.msg)
+	}
+}
+
+func TestBuild_GRPCProperties(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "GRPC test.Greeter/SayHello", LineIndex: 103, Properties: []ini.Property{
+			{Key: "body", Value: `{"message":"hello"}`, LineIndex: 104},
+			{Key: "grpc.status", Value: "NOT_FOUND", LineIndex: 105},
+			{Key: "grpc.message", Value: "user not found", LineIndex: 106},
+			{Key: "grpc.trailer", Value: "X-Request-Id: 42", LineIndex: 107},
+		}},
+		{Name: "GRPC /test.Greeter/SayBye", LineIndex: 108, Properties: []ini.Property{
+			{Key: "grpc.status", Value: "3", LineIndex: 109},
+		}},
+	}
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	assert.Equal(t, model.MethodGRPC, bs.Behaviors[0].Method)
+	assert.Equal(t, "/test.Greeter/SayHello", bs.Behaviors[0].URL)
+	assert.Equal(t, &model.GRPCResponse{
+		Code:     5,
+		Message:  "user not found",
+		Trailers: map[string]string{"X-Request-Id": "42"},
+	}, bs.Behaviors[0].GRPC)
+	assert.Equal(t, "/test.Greeter/SayBye", bs.Behaviors[1].URL)
+	assert.Equal(t, uint32(3), bs.Behaviors[1].GRPC.Code)
+}
+
+func TestBuild_GRPCInheritance(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "GRPC test.Greeter/SayHello", LineIndex: 1, Properties: []ini.Property{
+			{Key: "grpc.status", Value: "NOT_FOUND", LineIndex: 2},
+			{Key: "grpc.trailer", Value: "x-a: 1", LineIndex: 3},
+			{Key: "variant", Value: "1", LineIndex: 4},
+			{Key: "grpc.message", Value: "nope", LineIndex: 5},
+			{Key: "step", Value: "1", LineIndex: 6},
+			{Key: "grpc.trailer", Value: "x-b: 2", LineIndex: 7},
+			{Key: "variant", Value: "1", LineIndex: 8},
+			{Key: "grpc.status", Value: "OK", LineIndex: 9},
+		}},
+	}
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	behavior := bs.Behaviors[0]
+	assert.Equal(t, &model.GRPCResponse{Code: 5, Trailers: map[string]string{"x-a": "1"}}, behavior.GRPC)
+	require.Len(t, behavior.Variants, 1)
+	assert.Equal(t, &model.GRPCResponse{
+		Code: 5, Message: "nope", Trailers: map[string]string{"x-a": "1"},
+	}, behavior.Variants[0].GRPC)
+	require.Len(t, behavior.Steps, 1)
+	step := behavior.Steps[0]
+	assert.Equal(t, &model.GRPCResponse{Code: 5, Trailers: map[string]string{"x-a": "1", "x-b": "2"}}, step.GRPC)
+	require.Len(t, step.Variants, 1)
+	assert.Equal(t, &model.GRPCResponse{
+		Code: 0, Trailers: map[string]string{"x-a": "1", "x-b": "2"},
+	}, step.Variants[0].GRPC)
+}
+
+func TestBuild_GRPCPropertiesInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		properties []ini.Property
+		msg        string
+	}{
+		{"GET /grpc", []ini.Property{{Key: "grpc.status", Value: "OK"}}, "gRPC properties require a GRPC behavior"},
+		{"GRPC test.Greeter/SayHello", []ini.Property{{Key: "grpc.status", Value: "17"}}, "Invalid grpc.status"},
+		{"GRPC test.Greeter/SayHello", []ini.Property{{Key: "grpc.trailer", Value: "x"}}, "Invalid header format"},
+		{"GRPC test.Greeter/SayHello", []ini.Property{{Key: "grpc.unknown", Value: "x"}}, "Unknown gRPC property"},
+		{"GRPC test.Greeter", nil, "gRPC method must be in the format package.Service/Method"},
+		{"GRPC test.Greeter/", nil, "gRPC method must be in the format package.Service/Method"},
+	} {
+		sections := []ini.Section{{Name: tc.name, LineIndex: 110, Properties: tc.properties}}
+		_, err := Build(sections)
+		require.Error(t, err, tc.msg)
+		assert.Contains(t, err.Error(), tc.msg)
+	}
+}
+
+func TestBuild_GraphQLProperties(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "GRAPHQL /graphql", LineIndex: 111, Properties: []ini.Property{
+			{Key: "graphql.operation", Value: "GetUser", LineIndex: 112},
+			{Key: "graphql.type", Value: "Query", LineIndex: 113},
+			{Key: "graphql.variable", Value: "id: 42", LineIndex: 114},
+			{Key: "graphql.variable", Value: "name: Ada", LineIndex: 115},
+			{Key: "graphql.data", Value: `{"user":null}`, LineIndex: 116},
+			{Key: "variant", Value: "1", LineIndex: 117},
+			{Key: "graphql.errors", Value: "user not found", LineIndex: 118},
+		}},
+	}
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	behavior := bs.Behaviors[0]
+	query := model.GraphQLQuery
+	assert.Equal(t, model.MethodGraphQL, behavior.Method)
+	assert.Equal(t, &model.GraphQLMatch{
+		OperationName: Ptr("GetUser"),
+		OperationType: &query,
+		Variables:     map[string]any{"id": float64(42), "name": "Ada"},
+	}, behavior.GraphQLMatch)
+	assert.Equal(t, `{"user":null}`, *behavior.GraphQL.Data)
+	assert.Nil(t, behavior.GraphQL.Errors)
+	assert.Equal(t, `[{"message":"user not found"}]`, *behavior.Variants[0].GraphQL.Errors)
+}
+
+func TestBuild_GraphQLPropertiesInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		properties []ini.Property
+		msg        string
+	}{
+		{"POST /graphql", []ini.Property{{Key: "graphql.operation", Value: "A"}}, "GraphQL properties require a GRAPHQL behavior"},
+		{"GRAPHQL /graphql", []ini.Property{{Key: "graphql.operation", Value: ""}}, "Invalid graphql.operation"},
+		{"GRAPHQL /graphql", []ini.Property{{Key: "graphql.type", Value: "fragment"}}, "Invalid graphql.type"},
+		{"GRAPHQL /graphql", []ini.Property{{Key: "graphql.variable", Value: "id"}}, "Invalid graphql.variable format"},
+		{"GRAPHQL /graphql", []ini.Property{{Key: "graphql.data", Value: "{"}}, "Invalid graphql.data"},
+		{"GRAPHQL /graphql", []ini.Property{{Key: "graphql.errors", Value: "[{"}}, "Invalid graphql.errors"},
+		{"GRAPHQL /graphql", []ini.Property{{Key: "graphql.unknown", Value: "x"}}, "Unknown GraphQL property"},
+	} {
+		sections := []ini.Section{{Name: tc.name, LineIndex: 119, Properties: tc.properties}}
+		_, err := Build(sections)
+		require.Error(t, err, tc.msg)
+		assert.Contains(t, err.Error(), tc.msg)
+	}
+}
+
+const conformanceSpec = `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      responses:
+        "200":
+          description: Pet
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id:
+                    type: integer
+        "404":
+          description: Not found
+`
+
+func loadConformanceSpec(t *testing.T) *openapi3.T {
+	t.Helper()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(conformanceSpec))
+	require.NoError(t, err)
+	return doc
+}
+
+func TestBuild_Conformance(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "GET /pets/{id}", LineIndex: 120, Properties: []ini.Property{
+			{Key: "body", Value: `{"id":"one"}`, LineIndex: 121},
+			{Key: "variant", Value: "50", LineIndex: 122},
+			{Key: "status_code", Value: "500", LineIndex: 123},
+		}},
+		{Name: "GET /pets/1", LineIndex: 125, Properties: []ini.Property{
+			{Key: "status_code", Value: "404", LineIndex: 126},
+		}},
+		{Name: "GET /owners", LineIndex: 127},
+		{Name: "default", LineIndex: 128},
+	}
+
+	expected := []string{
+		`Response does not conform to the OpenAPI spec at line 121: body={"id":"one"} - Body does not match the schema: /id: value must be an integer`,
+		`Response does not conform to the OpenAPI spec at line 123: status_code=500 - Status code 500 is not declared for GET /pets/{id}`,
+		`Response does not conform to the OpenAPI spec at line 127: [GET /owners] - No operation GET /owners declared`,
+	}
+
+	_, err := Build(sections, WithSpec(loadConformanceSpec(t)))
+	require.Error(t, err)
+	var conformanceErr *ConformanceError
+	require.ErrorAs(t, err, &conformanceErr)
+	assert.Equal(t, &ConformanceError{
+		LineIndex: 121,
+		Line:      `body={"id":"one"}`,
+		Details:   "Body does not match the schema: /id: value must be an integer",
+	}, conformanceErr)
+	assert.EqualError(t, err, strings.Join(expected, "\n"))
+
+	_, err = Build(sections, WithSpec(loadConformanceSpec(t)), WithWarningHandler(func(error) {}))
+	assert.EqualError(t, err, strings.Join(expected, "\n"), "mismatches are not downgraded")
+
+	var warnings []string
+	bs, err := Build(sections, WithSpec(loadConformanceSpec(t)), WithWarnings(func(err error) {
+		warnings = append(warnings, err.Error())
+	}))
+	require.NoError(t, err)
+	assert.Len(t, bs.Behaviors, 3)
+	// [GET /pets/1] is also reported as shadowed by [GET /pets/{id}].
+	assert.Equal(t, []string{
+		expected[0],
+		expected[1],
+		"Warning at line 125: [GET /pets/1] - Behavior is never served, it is shadowed by [GET /pets/{id}] at line 120",
+		expected[2],
+	}, warnings)
+}
+
+func TestBuild_BodySchema(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pet.json"), []byte(`{
+		"type": "object",
+		"required": ["id"],
+		"properties": {"id": {"type": "integer"}, "tag": {"type": "string", "nullable": true}}
+	}`), 0o600))
+	source := filepath.Join(dir, "mocks.ini")
+	sections := []ini.Section{
+		{Name: "GET /pets/1", LineIndex: 1, Source: source, Properties: []ini.Property{
+			{Key: "body_schema", Value: "pet.json", LineIndex: 2, Source: source},
+			{Key: "body", Value: `{"id":1,"tag":null}`, LineIndex: 3, Source: source},
+			{Key: "step", Value: "1", LineIndex: 4, Source: source},
+			{Key: "body", Value: `{"id":"one"}`, LineIndex: 5, Source: source},
+			{Key: "step", Value: "1", LineIndex: 6, Source: source},
+			{Key: "variant", Value: "1", LineIndex: 7, Source: source},
+			{Key: "body", Value: `{}`, LineIndex: 8, Source: source},
+		}},
+		{Name: "GET /pets/2", LineIndex: 9, Source: source, Properties: []ini.Property{
+			{Key: "body_schema", Value: "missing.json", LineIndex: 10, Source: source},
+			{Key: "body", Value: `{}`, LineIndex: 11, Source: source},
+		}},
+		{Name: "GET /pets/3", LineIndex: 12, Source: source, Properties: []ini.Property{
+			{Key: "body_schema", Value: "", LineIndex: 13, Source: source},
+		}},
+	}
+
+	schema := filepath.Join(dir, "pet.json")
+	_, err := Build(sections)
+	require.Error(t, err)
+	var conformanceErr *ConformanceError
+	require.ErrorAs(t, err, &conformanceErr)
+	assert.Equal(t, &ConformanceError{
+		LineIndex: 5,
+		Source:    source,
+		Line:      `body={"id":"one"}`,
+		Details:   "Body does not match the schema: /id: value must be an integer",
+		Schema:    schema,
+	}, conformanceErr)
+	messages := strings.Split(err.Error(), "\n")
+	require.Len(t, messages, 4)
+	assert.Equal(t, "Response does not conform to the JSON Schema "+schema+" at line 5 of "+source+
+		`: body={"id":"one"} - Body does not match the schema: /id: value must be an integer`, messages[0])
+	assert.Equal(t, "Response does not conform to the JSON Schema "+schema+" at line 8 of "+source+
+		`: body={} - Body does not match the schema: /id: property "id" is missing`, messages[1])
+	assert.True(t, strings.HasPrefix(messages[2],
+		"Malformed property at line 10 of "+source+": body_schema=missing.json - failed to load JSON Schema"))
+	assert.Equal(t, "Malformed property at line 13 of "+source+": body_schema= - Missing path of the JSON Schema", messages[3])
+
+	var warnings []string
+	bs, err := Build(sections[:2], WithWarnings(func(err error) {
+		warnings = append(warnings, err.Error())
+	}))
+	require.Error(t, err, "schemas that fail to load are errors")
+	assert.Nil(t, bs)
+	assert.Len(t, warnings, 2)
+}
+
+func TestBuild_MatchProperties(t *testing.T) {
+	sections := []ini.Section{
+		{Name: "default"},
+		{Name: "POST /search", LineIndex: 1, Properties: []ini.Property{
+			{Key: "match.query", Value: "page=2&tag=a&tag=b"},
+			{Key: "match.body", Value: `{"term": "go"}`},
+		}},
+		{Name: "GET /empty", LineIndex: 4, Properties: []ini.Property{{Key: "match.query", Value: ""}}},
+	}
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	require.Len(t, bs.Behaviors, 2)
+	assert.Equal(t, []string{"a", "b"}, bs.Behaviors[0].Query["tag"])
+	assert.Equal(t, `{"term": "go"}`, *bs.Behaviors[0].RequestBody)
+	assert.NotNil(t, bs.Behaviors[1].Query)
+	assert.Empty(t, bs.Behaviors[1].Query)
+
+	_, err = Build([]ini.Section{{Name: "GET /x", Properties: []ini.Property{{Key: "match.query", Value: "a=%zz"}}}})
+	var malformed *MalformedPropertyError
+	assert.ErrorAs(t, err, &malformed)
+}
+
+func TestNormalize(t *testing.T) {
+	raw := `
+[GET /users]
+body = users
+cookie.name = session
+delay = 10ms
+header = X-A: 1
+# Session cookie value
+cookie.value = abc
+cookie.name = theme
+cookie.value = dark
+status_code = 200
+header = X-B: 2
+variant = 50
+body = other
+status_code = 500
+
+[vars]
+b = 2
+a = 1
+`
+	sections, err := ini.ParseRaw(strings.NewReader(raw))
+	require.NoError(t, err)
+
+	keys := func(section ini.Section) []string {
+		var keys []string
+		for _, property := range section.Properties {
+			keys = append(keys, property.Key+"="+property.Value)
+		}
+		return keys
+	}
+	normalized := Normalize(sections)
+	assert.Equal(t, []string{
+		"status_code=200", "header=X-A: 1", "header=X-B: 2",
+		"cookie.name=session", "cookie.value=abc", "cookie.name=theme", "cookie.value=dark",
+		"body=users", "delay=10ms",
+		"variant=50", "status_code=500", "body=other",
+	}, keys(normalized[1]))
+	assert.Equal(t, []string{"# Session cookie value"}, normalized[1].Properties[4].Comments)
+	assert.Equal(t, []string{"b=2", "a=1"}, keys(normalized[2]))
+
+	// Normalizing does not change the behaviors.
+	before, err := Build(sections[:2])
+	require.NoError(t, err)
+	after, err := Build(normalized[:2])
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+}
+
+func TestSections_RoundTrip(t *testing.T) {
+	raw := `
+status_code = 404
+body = not found
+
+[GET /users]
+repeat = 2
+header = Content-Type: application/json
+header = X-Trace: 1
+cookie.name = session
+cookie.value = abc
+cookie.path = /
+cookie.http_only = true
+cookie.same_site = strict
+body = [{"id": 1}]
+delay = uniform(10ms, 20ms)
+fault = truncate=10 25%
+variant = 3
+status_code = 500
+header = X-Trace: 2
+
+[POST /search]
+match.query = page=2
+match.body = {"term": "go"}
+sequence = loop
+step = 2
+status_code = 202
+step = 1
+status_code = 200
+delay = p50=10ms,p99=100ms
+
+[GET /events]
+sse.interval = 1s
+event = update
+event.id = 1
+event.data = {"a": 1}
+event.data = {"b": 2}
+
+[GET /stream]
+stream = ndjson
+chunk = {"a": 1}
+chunk.delay = 5ms
+bandwidth = 64KiB/s
+
+[WS /socket]
+ws.send = hello
+ws.close_after = 1m
+on = ^ping (\d+)$
+on.send = pong ${1}
+on.close = 1000 bye
+
+[GRPC demo.Users/Get]
+grpc.status = NOT_FOUND
+grpc.message = missing
+grpc.trailer = x-reason: gone
+variant = 1
+grpc.status = OK
+
+[GRAPHQL /graphql]
+graphql.operation = GetUser
+graphql.type = query
+graphql.variable = id: 1
+graphql.data = {"user": null}
+`
+	sections, err := ini.Parse(strings.NewReader(raw), true)
+	require.NoError(t, err)
+	expected, err := Build(sections)
+	require.NoError(t, err)
+
+	var out strings.Builder
+	require.NoError(t, ini.Write(&out, Sections(expected)))
+	written, err := ini.Parse(strings.NewReader(out.String()), true)
+	require.NoError(t, err)
+	actual, err := Build(written)
+	require.NoError(t, err)
+	// The written sections are at other lines than the parsed ones.
+	for i := range expected.Behaviors {
+		expected.Behaviors[i].Origin, actual.Behaviors[i].Origin = model.Origin{}, model.Origin{}
+	}
+	assert.Equal(t, expected, actual)
+
+	assert.Contains(t, out.String(), "[POST /search]\nsequence = loop\nmatch.query = page=2\n")
+	assert.Contains(t, out.String(), "variant = 3\nstatus_code = 500\nheader = X-Trace: 2\n")
+	assert.NotContains(t, out.String(), "header = Content-Type: application/json\nheader = X-Trace: 2")
+	assert.Contains(t, out.String(), "variant = 1\ngrpc.status = 0\n\n")
+}
+
+func TestSections_BodySchema(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mocks")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "schemas"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schemas", "pet.json"), []byte(`{"type": "object"}`), 0o600))
+	path := filepath.Join(dir, "pets.ini")
+	require.NoError(t, os.WriteFile(path, []byte("[GET /pets/1]\nbody = {}\nbody_schema = schemas/pet.json\n"), 0o600))
+
+	sections, err := ini.ParseFile(path, true)
+	require.NoError(t, err)
+	bs, err := Build(sections)
+	require.NoError(t, err)
+	assert.Equal(t, &model.BodySchema{Ref: "schemas/pet.json", Path: filepath.Join(dir, "schemas", "pet.json")},
+		bs.Behaviors[0].BodySchema)
+
+	var out strings.Builder
+	require.NoError(t, ini.Write(&out, Sections(bs)))
+	assert.Contains(t, out.String(), "body_schema = schemas/pet.json\n", "the path is written as in the config")
+	require.NoError(t, os.WriteFile(path, []byte(out.String()), 0o600))
+	sections, err = ini.ParseFile(path, true)
+	require.NoError(t, err)
+	rebuilt, err := Build(sections)
+	require.NoError(t, err)
+	assert.Equal(t, bs.Behaviors[0].BodySchema, rebuilt.Behaviors[0].BodySchema)
+}
+
+func TestSections_MultilineBody(t *testing.T) {
+	bs := &model.BehaviorSet{Behaviors: []*model.Behavior{{
+		Method: model.MethodGet,
+		URL:    "/json",
+		ResponseBehavior: &model.ResponseBehavior{
+			Body:     Ptr("{\n  \"id\": 1\n}"),
+			Redirect: Ptr("/login"),
+		},
+	}, {
+		Method:           model.MethodGet,
+		URL:              "/text",
+		ResponseBehavior: &model.ResponseBehavior{Body: Ptr("line 1\nline 2")},
+	}}}
+
+	sections := Sections(bs)
+	assert.Equal(t, []ini.Property{
+		{Key: "header", Value: "Location: /login"},
+		{Key: "body", Value: `{"id":1}`},
+	}, sections[1].Properties)
+
+	var multiline *ini.MultilineValueError
+	assert.ErrorAs(t, ini.Write(&strings.Builder{}, sections), &multiline)
+}
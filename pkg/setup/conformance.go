@@ -0,0 +1,197 @@
+package setup
+
+import (
+	"strings"
+
+	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/model"
+	"github.com/StevenCyb/ServMock/pkg/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Option configures Build.
+type Option func(*buildOptions)
+
+type buildOptions struct {
+	spec         *openapi3.T
+	specWarnings bool
+	warn         func(error)
+}
+
+// WithSpec checks the status code, headers and body of every behavior
+// against the responses its operation declares in the OpenAPI spec.
+func WithSpec(doc *openapi3.T) Option {
+	return func(options *buildOptions) {
+		options.spec = doc
+	}
+}
+
+// WithWarningHandler reports problems that do not block loading to warn, see Warning.
+// Mismatches with the spec and the JSON Schemas of bodies still fail the build.
+func WithWarningHandler(warn func(error)) Option {
+	return func(options *buildOptions) {
+		options.warn = warn
+	}
+}
+
+// WithWarnings reports mismatches with the spec and the JSON Schemas of bodies to warn instead of
+// failing the build, along with the problems reported by WithWarningHandler.
+func WithWarnings(warn func(error)) Option {
+	return func(options *buildOptions) {
+		options.warn = warn
+		options.specWarnings = true
+	}
+}
+
+func (options *buildOptions) report(warning error) {
+	if options.warn != nil {
+		options.warn(warning)
+	}
+}
+
+// checkConformance checks every response a behavior can serve against the spec and returns the mismatches.
+// Mismatches are reported at the line of the property causing them, which may be inherited
+// from the enclosing step or behavior, or at the section header.
+func checkConformance(spec *openapi3.T, section ini.Section, behavior *model.Behavior, origins origins) []error {
+	var mismatches []error
+	reported := map[string]bool{}
+	for _, chain := range responseChains(behavior) {
+		for _, mismatch := range openapi.CheckResponse(spec, behavior.Method, behavior.URL, chain[0]) {
+			err := &ConformanceError{
+				LineIndex: section.LineIndex,
+				Column:    section.Column,
+				Source:    section.Source,
+				Line:      "[" + section.Name + "]",
+				Details:   mismatch.Message,
+			}
+			if property, found := lookupOrigin(origins, chain, mismatch); found {
+				err.LineIndex, err.Column, err.Source = property.LineIndex, property.Column, property.Source
+				err.Line = property.Key + "=" + property.Value
+			}
+			if reported[err.Error()] {
+				continue
+			}
+			reported[err.Error()] = true
+			mismatches = append(mismatches, err)
+		}
+	}
+	return mismatches
+}
+
+// bodySchemas holds the JSON Schemas of body_schema properties by path, nil if loading failed.
+type bodySchemas map[string]*openapi3.Schema
+
+// check checks the body of every response a behavior can serve against its JSON Schema and returns
+// the mismatches like checkConformance. Schemas that fail to load are returned as errors once per path.
+func (schemas bodySchemas) check(section ini.Section, behavior *model.Behavior, origins origins) ([]error, []error) {
+	var mismatches, errs []error
+	reported := map[string]bool{}
+	for _, chain := range responseChains(behavior) {
+		if chain[0].BodySchema == nil {
+			continue
+		}
+		path := chain[0].BodySchema.Path
+		schema, loaded := schemas[path]
+		if !loaded {
+			var err error
+			if schema, err = openapi.LoadSchema(path); err != nil {
+				property, _ := lookupOrigin(origins, chain, openapi.Mismatch{Property: "body_schema"})
+				errs = append(errs, &MalformedPropertyError{
+					LineIndex: property.LineIndex,
+					Column:    property.Column,
+					Source:    property.Source,
+					Line:      property.Key + "=" + property.Value,
+					Details:   Ptr(err.Error()),
+				})
+			}
+			schemas[path] = schema
+		}
+		if schema == nil {
+			continue
+		}
+
+		for _, mismatch := range openapi.CheckSchema(schema, chain[0]) {
+			err := &ConformanceError{
+				LineIndex: section.LineIndex,
+				Column:    section.Column,
+				Source:    section.Source,
+				Line:      "[" + section.Name + "]",
+				Details:   mismatch.Message,
+				Schema:    path,
+			}
+			if property, found := lookupOrigin(origins, chain, mismatch); found {
+				err.LineIndex, err.Column, err.Source = property.LineIndex, property.Column, property.Source
+				err.Line = property.Key + "=" + property.Value
+			}
+			if reported[err.Error()] {
+				continue
+			}
+			reported[err.Error()] = true
+			mismatches = append(mismatches, err)
+		}
+	}
+	return mismatches, errs
+}
+
+// responseChains returns every response a behavior can serve, each followed by the responses enclosing it,
+// to look up the lines of inherited properties.
+func responseChains(behavior *model.Behavior) [][]*model.ResponseBehavior {
+	var chains [][]*model.ResponseBehavior
+	addWithVariants := func(chain []*model.ResponseBehavior) {
+		chains = append(chains, chain)
+		for _, variant := range chain[0].Variants {
+			chains = append(chains, append([]*model.ResponseBehavior{variant.ResponseBehavior}, chain...))
+		}
+	}
+	if len(behavior.Steps) == 0 {
+		addWithVariants([]*model.ResponseBehavior{behavior.ResponseBehavior})
+	}
+	for _, step := range behavior.Steps {
+		addWithVariants([]*model.ResponseBehavior{step.ResponseBehavior, behavior.ResponseBehavior})
+	}
+	return chains
+}
+
+// origins maps response behaviors to their status_code, body, body_schema and header properties.
+// Header properties are stored as "header:<lowercase name>".
+type origins map[*model.ResponseBehavior]map[string]ini.Property
+
+func (o origins) record(target *model.ResponseBehavior, property ini.Property) {
+	key := property.Key
+	switch key {
+	case "status_code", "body", "body_schema":
+	case "header":
+		name, _, err := splitHeader(property)
+		if err != nil {
+			return
+		}
+		key = "header:" + strings.ToLower(name)
+	default:
+		return
+	}
+	if o[target] == nil {
+		o[target] = map[string]ini.Property{}
+	}
+	o[target][key] = property
+}
+
+// lookupOrigin finds the property causing a mismatch, starting at the innermost response of the chain.
+func lookupOrigin(
+	origins origins,
+	chain []*model.ResponseBehavior,
+	mismatch openapi.Mismatch,
+) (ini.Property, bool) {
+	key := mismatch.Property
+	if key == "" {
+		return ini.Property{}, false
+	}
+	if key == "header" {
+		key = "header:" + strings.ToLower(mismatch.Header)
+	}
+	for _, response := range chain {
+		if property, found := origins[response][key]; found {
+			return property, true
+		}
+	}
+	return ini.Property{}, false
+}
@@ -5,6 +5,8 @@ import "strconv"
 // MalformedBehaviorHeaderError indicates an error in the behavior header format.
 type MalformedBehaviorHeaderError struct {
 	LineIndex uint64
+	Column    uint64
+	Source    string
 	Line      string
 	Details   *string
 }
@@ -12,14 +14,16 @@ type MalformedBehaviorHeaderError struct {
 // Error returns a string representation of the MalformedBehaviorHeaderError.
 func (e *MalformedBehaviorHeaderError) Error() string {
 	if e.Details != nil {
-		return "Malformed behavior header at line " + strconv.FormatUint(e.LineIndex, 10) + ": " + e.Line + " - " + *e.Details
+		return "Malformed behavior header at " + location(e.Source, e.LineIndex, e.Column) + ": " + e.Line + " - " + *e.Details
 	}
-	return "Malformed behavior header at line " + strconv.FormatUint(e.LineIndex, 10) + ": " + e.Line
+	return "Malformed behavior header at " + location(e.Source, e.LineIndex, e.Column) + ": " + e.Line
 }
 
 // MalformedPropertyError indicates an error in the property format.
 type MalformedPropertyError struct {
 	LineIndex uint64
+	Column    uint64
+	Source    string
 	Line      string
 	Details   *string
 }
@@ -27,7 +31,57 @@ type MalformedPropertyError struct {
 // Error returns a string representation of the MalformedPropertyError.
 func (e *MalformedPropertyError) Error() string {
 	if e.Details != nil {
-		return "Malformed property at line " + strconv.FormatUint(e.LineIndex, 10) + ": " + e.Line + " - " + *e.Details
+		return "Malformed property at " + location(e.Source, e.LineIndex, e.Column) + ": " + e.Line + " - " + *e.Details
 	}
-	return "Malformed property at line " + strconv.FormatUint(e.LineIndex, 10) + ": " + e.Line
+	return "Malformed property at " + location(e.Source, e.LineIndex, e.Column) + ": " + e.Line
+}
+
+// ConformanceError indicates a behavior response that does not match the OpenAPI spec or,
+// if Schema is set, the JSON Schema at that path.
+type ConformanceError struct {
+	LineIndex uint64
+	Column    uint64
+	Source    string
+	Line      string
+	Details   string
+	Schema    string
+}
+
+// Error returns a string representation of the ConformanceError.
+func (e *ConformanceError) Error() string {
+	return "Response does not conform to " + e.Target() + " at " + location(e.Source, e.LineIndex, e.Column) + ": " + e.Line + " - " + e.Details
+}
+
+// Target names what the response was checked against, like `the OpenAPI spec`.
+func (e *ConformanceError) Target() string {
+	if e.Schema != "" {
+		return "the JSON Schema " + e.Schema
+	}
+	return "the OpenAPI spec"
+}
+
+// Warning indicates a problem that does not prevent the configuration from loading.
+type Warning struct {
+	LineIndex uint64
+	Column    uint64
+	Source    string
+	Line      string
+	Details   string
+}
+
+// Error returns a string representation of the Warning.
+func (e *Warning) Error() string {
+	return "Warning at " + location(e.Source, e.LineIndex, e.Column) + ": " + e.Line + " - " + e.Details
+}
+
+// location describes a line and column if known, followed by its file if known.
+func location(source string, lineIndex uint64, column uint64) string {
+	position := "line " + strconv.FormatUint(lineIndex, 10)
+	if column > 0 {
+		position += ", column " + strconv.FormatUint(column, 10)
+	}
+	if source == "" {
+		return position
+	}
+	return position + " of " + source
 }
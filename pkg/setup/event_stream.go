@@ -0,0 +1,84 @@
+package setup
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+// parseEventStream handles `sse.*` stream properties and `event`/`event.*` event properties.
+// Like cookies, `event = <type>` starts a new event and following `event.*` properties belong to it.
+//
+//nolint:cyclop,funlen
+func parseEventStream(responseBehavior *model.ResponseBehavior, property ini.Property) error {
+	malformed := func(details string) error {
+		return &MalformedPropertyError{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   Ptr(details),
+		}
+	}
+
+	if responseBehavior.EventStream == nil {
+		responseBehavior.EventStream = &model.EventStream{}
+	}
+	responseBehavior.SSE = true
+	stream := responseBehavior.EventStream
+
+	var event *model.Event
+	switch {
+	case property.Key == "event":
+		stream.Events = append(stream.Events, &model.Event{Type: property.Value})
+		return nil
+	case strings.HasPrefix(property.Key, "sse."):
+	case len(stream.Events) == 0:
+		return malformed("event must be set before other event properties")
+	default:
+		event = stream.Events[len(stream.Events)-1]
+	}
+
+	switch property.Key {
+	case "sse.interval", "sse.keep_alive", "event.delay":
+		duration, err := time.ParseDuration(property.Value)
+		if err != nil || duration < 0 {
+			return malformed("Invalid " + property.Key + ", must be a non-negative duration")
+		}
+		switch property.Key {
+		case "sse.interval":
+			stream.Interval = &duration
+		case "sse.keep_alive":
+			if duration == 0 {
+				return malformed("Invalid sse.keep_alive, must be a positive duration")
+			}
+			stream.KeepAlive = &duration
+		default:
+			event.Delay = &duration
+		}
+	case "sse.loop":
+		stream.Loop = strings.EqualFold(property.Value, "true")
+	case "sse.hold":
+		stream.Hold = strings.EqualFold(property.Value, "true")
+	case "event.id":
+		if strings.ContainsAny(property.Value, "\r\n\x00") {
+			return malformed("Invalid event.id, must not contain line breaks or NULL")
+		}
+		event.ID = Ptr(property.Value)
+	case "event.data":
+		event.Data = append(event.Data, property.Value)
+	case "event.retry":
+		retry, err := strconv.Atoi(property.Value)
+		if err != nil || retry < 0 {
+			return malformed("Invalid event.retry, must be a non-negative integer of milliseconds")
+		}
+		event.Retry = Ptr(uint(retry))
+	default:
+		return malformed("Unknown event stream property: " + property.Key)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package setup
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+// parseGraphQL handles the `graphql.*` properties of GRAPHQL behaviors.
+// Operation, type and variable properties restrict which operations the behavior matches,
+// while data and errors define the result and can differ per step or variant.
+//
+//nolint:cyclop
+func parseGraphQL(behavior *model.Behavior, responseBehavior *model.ResponseBehavior, property ini.Property) error {
+	malformed := func(details string) error {
+		return &MalformedPropertyError{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   Ptr(details),
+		}
+	}
+
+	if behavior.Method != model.MethodGraphQL {
+		return malformed("GraphQL properties require a GRAPHQL behavior")
+	}
+	if behavior.GraphQLMatch == nil {
+		behavior.GraphQLMatch = &model.GraphQLMatch{}
+	}
+	if responseBehavior.GraphQL == nil {
+		responseBehavior.GraphQL = &model.GraphQLResponse{}
+	}
+
+	switch property.Key {
+	case "graphql.operation":
+		if property.Value == "" {
+			return malformed("Invalid graphql.operation, the operation name cannot be empty")
+		}
+		behavior.GraphQLMatch.OperationName = Ptr(property.Value)
+	case "graphql.type":
+		operationType, match := model.GraphQLOperationTypeFromString(property.Value)
+		if !match {
+			return malformed("Invalid graphql.type, expected query, mutation or subscription")
+		}
+		behavior.GraphQLMatch.OperationType = &operationType
+	case "graphql.variable":
+		name, value, found := strings.Cut(property.Value, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return malformed("Invalid graphql.variable format, expected 'name: value'")
+		}
+		if behavior.GraphQLMatch.Variables == nil {
+			behavior.GraphQLMatch.Variables = make(map[string]any)
+		}
+		behavior.GraphQLMatch.Variables[name] = parseJSONValue(strings.TrimSpace(value))
+	case "graphql.data":
+		if !json.Valid([]byte(property.Value)) {
+			return malformed("Invalid graphql.data, must be valid JSON")
+		}
+		responseBehavior.GraphQL.Data = Ptr(property.Value)
+	case "graphql.errors":
+		errors := property.Value
+		if !strings.HasPrefix(strings.TrimSpace(errors), "[") {
+			// A plain text value is a shorthand for a single error message.
+			raw, _ := json.Marshal([]map[string]string{{"message": errors}})
+			errors = string(raw)
+		}
+		if !json.Valid([]byte(errors)) {
+			return malformed("Invalid graphql.errors, must be a JSON array or an error message")
+		}
+		responseBehavior.GraphQL.Errors = Ptr(errors)
+	default:
+		return malformed("Unknown GraphQL property: " + property.Key)
+	}
+
+	return nil
+}
+
+// parseJSONValue decodes a JSON value and falls back to the raw string if it is not valid JSON.
+func parseJSONValue(value string) any {
+	var decoded any
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
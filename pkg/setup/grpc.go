@@ -0,0 +1,60 @@
+package setup
+
+import (
+	"maps"
+
+	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+// parseGRPC handles the `grpc.*` status and trailer properties of GRPC behaviors.
+func parseGRPC(behavior *model.Behavior, responseBehavior *model.ResponseBehavior, property ini.Property) error {
+	malformed := func(details string) error {
+		return &MalformedPropertyError{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   Ptr(details),
+		}
+	}
+
+	if behavior.Method != model.MethodGRPC {
+		return malformed("gRPC properties require a GRPC behavior")
+	}
+	if responseBehavior.GRPC == nil {
+		// Steps and variants start from the status they inherit, so setting one property keeps the others.
+		responseBehavior.GRPC = &model.GRPCResponse{}
+		for _, parent := range enclosingResponses(behavior, responseBehavior) {
+			if parent.GRPC != nil {
+				*responseBehavior.GRPC = *parent.GRPC
+				responseBehavior.GRPC.Trailers = maps.Clone(parent.GRPC.Trailers)
+				break
+			}
+		}
+	}
+
+	switch property.Key {
+	case "grpc.status":
+		code, match := model.GRPCCodeFromString(property.Value)
+		if !match {
+			return malformed("Invalid grpc.status, expected a code name like NOT_FOUND or a number between 0 and 16")
+		}
+		responseBehavior.GRPC.Code = code
+	case "grpc.message":
+		responseBehavior.GRPC.Message = property.Value
+	case "grpc.trailer":
+		key, value, err := splitHeader(property)
+		if err != nil {
+			return err
+		}
+		if responseBehavior.GRPC.Trailers == nil {
+			responseBehavior.GRPC.Trailers = make(map[string]string)
+		}
+		responseBehavior.GRPC.Trailers[key] = value
+	default:
+		return malformed("Unknown gRPC property: " + property.Key)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package setup
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+const maxPercentile = 100
+
+// parseDelayDistribution parses a delay distribution like `uniform(100ms, 500ms)`,
+// `normal(200ms, 50ms)`, `lognormal(200ms, 100ms)` or `p50=20ms,p99=800ms`.
+func parseDelayDistribution(responseBehavior *model.ResponseBehavior, property ini.Property) error {
+	malformed := func(details string) error {
+		return &MalformedPropertyError{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   Ptr(details),
+		}
+	}
+
+	value := strings.TrimSpace(property.Value)
+	if strings.Contains(value, "=") {
+		latency, err := parsePercentiles(value, malformed)
+		if err != nil {
+			return err
+		}
+		responseBehavior.Latency = latency
+		responseBehavior.Delay = nil
+		return nil
+	}
+
+	open := strings.Index(value, "(")
+	if open == -1 || !strings.HasSuffix(value, ")") {
+		return malformed("Invalid delay, must be a non-negative duration or distribution")
+	}
+
+	distribution, match := model.LatencyDistributionFromString(strings.TrimSpace(value[:open]))
+	if !match {
+		return malformed("Unknown delay distribution: " + strings.TrimSpace(value[:open]))
+	}
+
+	args := strings.Split(value[open+1:len(value)-1], ",")
+	if len(args) != twoParts {
+		return malformed("Delay distribution " + string(distribution) + " requires exactly two durations")
+	}
+
+	params := make([]time.Duration, 0, len(args))
+	for _, arg := range args {
+		param, err := time.ParseDuration(strings.TrimSpace(arg))
+		if err != nil || param < 0 {
+			return malformed("Invalid delay distribution parameter, must be a non-negative duration")
+		}
+		params = append(params, param)
+	}
+
+	if distribution == model.LatencyUniform && params[0] > params[1] {
+		return malformed("Invalid uniform delay, min cannot be greater than max")
+	}
+	if distribution == model.LatencyLogNormal && params[0] == 0 {
+		return malformed("Invalid lognormal delay, mean must be positive")
+	}
+
+	responseBehavior.Latency = &model.Latency{Distribution: distribution, Params: params}
+	responseBehavior.Delay = nil
+	return nil
+}
+
+func parsePercentiles(value string, malformed func(string) error) (*model.Latency, error) {
+	latency := &model.Latency{Distribution: model.LatencyPercentiles}
+
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), "=", twoParts)
+		if len(parts) != twoParts || !strings.HasPrefix(strings.ToLower(parts[0]), "p") {
+			return nil, malformed("Invalid delay percentile, expected 'p<percentile>=<duration>'")
+		}
+
+		percentile, err := strconv.ParseFloat(strings.TrimSpace(parts[0])[1:], 64)
+		if err != nil || percentile < 0 || percentile > maxPercentile {
+			return nil, malformed("Invalid delay percentile, must be between p0 and p100")
+		}
+		delay, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || delay < 0 {
+			return nil, malformed("Invalid delay percentile duration, must be a non-negative duration")
+		}
+
+		latency.Percentiles = append(latency.Percentiles, model.Percentile{Percentile: percentile, Delay: delay})
+	}
+
+	slices.SortFunc(latency.Percentiles, func(a, b model.Percentile) int {
+		return cmp.Compare(a.Percentile, b.Percentile)
+	})
+	for i := 1; i < len(latency.Percentiles); i++ {
+		if latency.Percentiles[i].Delay < latency.Percentiles[i-1].Delay {
+			return nil, malformed("Invalid delay percentiles, delays must not decrease with higher percentiles")
+		}
+	}
+
+	return latency, nil
+}
@@ -0,0 +1,122 @@
+package setup
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/StevenCyb/ServMock/pkg/ini"
+)
+
+// propertyOrder is the canonical order of property groups, properties of a group keep their relative order.
+// Streaming properties share a group since `sse` and `stream` override each other in order of appearance.
+//
+//nolint:gochecknoglobals
+var propertyOrder = [][]string{
+	{"inherit_headers"},
+	{"repeat"},
+	{"sequence"},
+	{"match.query"},
+	{"match.body"},
+	{"graphql.operation", "graphql.type", "graphql.variable"},
+	{"status_code"},
+	{"redirect"},
+	{"header"},
+	{"cookie"},
+	{"body"},
+	{"body_schema"},
+	{"graphql.data", "graphql.errors"},
+	{"grpc.status", "grpc.message", "grpc.trailer"},
+	{"delay"},
+	{"chunk_delay"},
+	{"chunk_size"},
+	{"bandwidth"},
+	{"proxy"},
+	{"proxy_header"},
+	{"fault"},
+	{"sse", "event", "stream", "chunk"},
+	{"ws"},
+	{"on"},
+}
+
+// unitLeads are the properties starting a unit like a cookie, which the following properties
+// with the same prefix belong to, e.g. `cookie.value` to the last `cookie.name`.
+//
+//nolint:gochecknoglobals
+var unitLeads = map[string]string{
+	"cookie.name": "cookie.",
+	"event":       "event.",
+	"chunk":       "chunk.",
+	"on":          "on.",
+}
+
+// Normalize returns the sections with their properties in canonical order.
+// Properties are sorted by their group in propertyOrder, unknown ones last. A `step`, `variant` or
+// `@include` property ends a run of properties that is sorted on its own, since the properties
+// following it apply to it. Properties belonging to a unit are moved right after it, so for
+// example all properties of a cookie end up together. Comments move with their property.
+// Sections whose order of properties is meaningful, like vars, are left as they are.
+func Normalize(sections []ini.Section) []ini.Section {
+	normalized := make([]ini.Section, len(sections))
+	for i, section := range sections {
+		if section.Name != ini.VarsSection {
+			section.Properties = normalizeProperties(section.Properties)
+		}
+		normalized[i] = section
+	}
+	return normalized
+}
+
+func normalizeProperties(properties []ini.Property) []ini.Property {
+	result := make([]ini.Property, 0, len(properties))
+	start := 0
+	for i, property := range properties {
+		if property.Key == "step" || property.Key == "variant" || property.Key == ini.IncludeKey {
+			result = append(result, sortRun(properties[start:i])...)
+			result = append(result, property)
+			start = i + 1
+		}
+	}
+	return append(result, sortRun(properties[start:])...)
+}
+
+// sortRun groups the properties of a run into units and sorts them by the rank of their first property.
+func sortRun(properties []ini.Property) []ini.Property {
+	var units [][]ini.Property
+	// lastUnit holds the index of the last unit started per unit property prefix.
+	lastUnit := map[string]int{}
+	for _, property := range properties {
+		if prefix, lead := unitLeads[property.Key]; lead {
+			lastUnit[prefix] = len(units)
+			units = append(units, []ini.Property{property})
+			continue
+		}
+		if prefix, _, found := strings.Cut(property.Key, "."); found {
+			if index, started := lastUnit[prefix+"."]; started {
+				units[index] = append(units[index], property)
+				continue
+			}
+		}
+		units = append(units, []ini.Property{property})
+	}
+
+	slices.SortStableFunc(units, func(a, b []ini.Property) int {
+		return propertyRank(a[0].Key) - propertyRank(b[0].Key)
+	})
+	return slices.Concat(units...)
+}
+
+// propertyRank returns the index of the group of a property in propertyOrder.
+func propertyRank(key string) int {
+	group, _, _ := strings.Cut(key, ".")
+	for rank, keys := range propertyOrder {
+		if slices.Contains(keys, key) {
+			return rank
+		}
+	}
+	for rank, keys := range propertyOrder {
+		if slices.Contains(keys, group) {
+			return rank
+		}
+	}
+	return len(propertyOrder)
+}
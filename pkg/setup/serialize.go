@@ -0,0 +1,338 @@
+package setup
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+// Sections returns the INI sections Build turns into the given behavior set, e.g. to persist
+// behaviors built from a HAR archive or an OpenAPI spec. Properties are in canonical order, see Normalize.
+// Steps and variants only list what they do not inherit. Cookie expirations are written as absolute
+// cookie.raw_expires and a redirect as Location header. Multi-line JSON bodies are compacted,
+// other multi-line values are kept and rejected by ini.Write.
+func Sections(bs *model.BehaviorSet) []ini.Section {
+	sections := []ini.Section{{Name: "default"}}
+	if bs.DefaultBehavior != nil {
+		sections[0].Properties = (&serializer{}).response(bs.DefaultBehavior, nil).properties
+	}
+	for _, behavior := range bs.Behaviors {
+		sections = append(sections, ini.Section{
+			Name:       string(behavior.Method) + " " + behavior.URL,
+			Properties: (&serializer{}).behavior(behavior).properties,
+		})
+	}
+	return sections
+}
+
+// serializer collects the properties of a behavior.
+type serializer struct {
+	properties []ini.Property
+}
+
+func (s *serializer) add(key, value string) {
+	s.properties = append(s.properties, ini.Property{Key: key, Value: value})
+}
+
+func (s *serializer) behavior(behavior *model.Behavior) *serializer {
+	if behavior.Repeat != nil {
+		s.add("repeat", strconv.FormatUint(uint64(*behavior.Repeat), 10))
+	}
+	if behavior.Loop {
+		s.add("sequence", "loop")
+	}
+	if behavior.Query != nil {
+		s.add("match.query", behavior.Query.Encode())
+	}
+	if behavior.RequestBody != nil {
+		s.add("match.body", singleLine(*behavior.RequestBody))
+	}
+	if match := behavior.GraphQLMatch; match != nil {
+		if match.OperationName != nil {
+			s.add("graphql.operation", *match.OperationName)
+		}
+		if match.OperationType != nil {
+			s.add("graphql.type", string(*match.OperationType))
+		}
+		for _, name := range slices.Sorted(maps.Keys(match.Variables)) {
+			raw, _ := json.Marshal(match.Variables[name])
+			s.add("graphql.variable", name+": "+string(raw))
+		}
+	}
+
+	s.response(behavior.ResponseBehavior, nil)
+	for _, step := range behavior.Steps {
+		s.add("step", strconv.FormatUint(uint64(step.Count), 10))
+		s.response(step.ResponseBehavior, behavior.ResponseBehavior)
+	}
+	return s
+}
+
+// response adds the properties of a response behavior that differ from the parent it inherits from.
+//
+//nolint:cyclop,funlen
+func (s *serializer) response(rb, parent *model.ResponseBehavior) *serializer {
+	if parent == nil {
+		parent = &model.ResponseBehavior{}
+	}
+
+	if rb.StatusCode != nil && rb.StatusCode != parent.StatusCode {
+		s.add("status_code", strconv.Itoa(int(*rb.StatusCode)))
+	}
+	if rb.Redirect != nil && rb.Redirect != parent.Redirect {
+		s.add("header", "Location: "+*rb.Redirect)
+	}
+	s.headers("header", rb.Headers, parent.Headers)
+	if !sameSlice(rb.Cookies, parent.Cookies) {
+		for _, cookie := range rb.Cookies {
+			s.cookie(cookie)
+		}
+	}
+	if rb.Body != nil && rb.Body != parent.Body {
+		s.add("body", singleLine(*rb.Body))
+	}
+	if rb.BodySchema != nil && rb.BodySchema != parent.BodySchema {
+		s.add("body_schema", rb.BodySchema.Ref)
+	}
+	if rb.GraphQL != nil && rb.GraphQL != parent.GraphQL {
+		if rb.GraphQL.Data != nil {
+			s.add("graphql.data", singleLine(*rb.GraphQL.Data))
+		}
+		if rb.GraphQL.Errors != nil {
+			s.add("graphql.errors", singleLine(*rb.GraphQL.Errors))
+		}
+	}
+	if rb.GRPC != nil && rb.GRPC != parent.GRPC {
+		inherited := parent.GRPC
+		if inherited == nil {
+			inherited = &model.GRPCResponse{}
+		}
+		if rb.GRPC.Code != inherited.Code {
+			s.add("grpc.status", strconv.FormatUint(uint64(rb.GRPC.Code), 10))
+		}
+		if rb.GRPC.Message != inherited.Message {
+			s.add("grpc.message", rb.GRPC.Message)
+		}
+		s.headers("grpc.trailer", rb.GRPC.Trailers, inherited.Trailers)
+	}
+
+	if rb.Delay != parent.Delay || rb.Latency != parent.Latency {
+		if rb.Delay != nil {
+			s.add("delay", rb.Delay.String())
+		}
+		if rb.Latency != nil {
+			s.add("delay", latency(rb.Latency))
+		}
+	}
+	if rb.ChunkDelay != nil && rb.ChunkDelay != parent.ChunkDelay {
+		s.add("chunk_delay", rb.ChunkDelay.String())
+	}
+	if rb.ChunkSize != nil && rb.ChunkSize != parent.ChunkSize {
+		s.add("chunk_size", strconv.FormatUint(*rb.ChunkSize, 10))
+	}
+	if rb.Bandwidth != nil && rb.Bandwidth != parent.Bandwidth {
+		s.add("bandwidth", strconv.FormatUint(*rb.Bandwidth, 10)+"/s")
+	}
+	if rb.Proxy != nil && rb.Proxy != parent.Proxy {
+		s.add("proxy", *rb.Proxy)
+	}
+	s.headers("proxy_header", rb.ProxyHeaders, parent.ProxyHeaders)
+	if !sameSlice(rb.Faults, parent.Faults) {
+		for _, fault := range rb.Faults {
+			s.add("fault", faultValue(fault))
+		}
+	}
+
+	if rb.Stream != nil && rb.Stream != parent.Stream {
+		s.stream(rb.Stream)
+	}
+	sseImplied := rb.EventStream != nil || (rb.Stream != nil && rb.Stream.Mode == model.StreamSSE)
+	if rb.SSE && !parent.SSE && !sseImplied {
+		s.add("sse", "true")
+	}
+	if rb.EventStream != nil && rb.EventStream != parent.EventStream {
+		s.eventStream(rb.EventStream)
+	}
+	if rb.WebSocket != nil && rb.WebSocket != parent.WebSocket {
+		s.webSocket(rb.WebSocket)
+	}
+
+	if !sameSlice(rb.Variants, parent.Variants) {
+		for _, variant := range rb.Variants {
+			s.add("variant", strconv.FormatUint(uint64(variant.Weight), 10))
+			s.response(variant.ResponseBehavior, rb)
+		}
+	}
+	return s
+}
+
+// headers adds a property per header in lexical order, leaving out the ones equal to the inherited headers.
+func (s *serializer) headers(key string, headers, inherited map[string]string) {
+	for _, name := range slices.Sorted(maps.Keys(headers)) {
+		if value, found := inherited[name]; !found || value != headers[name] {
+			s.add(key, name+": "+headers[name])
+		}
+	}
+}
+
+func (s *serializer) cookie(cookie *http.Cookie) {
+	s.add("cookie.name", cookie.Name)
+	s.add("cookie.value", cookie.Value)
+	if cookie.Path != "" {
+		s.add("cookie.path", cookie.Path)
+	}
+	if cookie.Domain != "" {
+		s.add("cookie.domain", cookie.Domain)
+	}
+	if cookie.RawExpires != "" {
+		s.add("cookie.raw_expires", cookie.RawExpires)
+	} else if !cookie.Expires.IsZero() {
+		s.add("cookie.raw_expires", cookie.Expires.UTC().Format(time.RFC3339))
+	}
+	if cookie.MaxAge != 0 {
+		s.add("cookie.max_age", strconv.Itoa(cookie.MaxAge))
+	}
+	if cookie.Secure {
+		s.add("cookie.secure", "true")
+	}
+	if cookie.HttpOnly {
+		s.add("cookie.http_only", "true")
+	}
+	switch cookie.SameSite {
+	case http.SameSiteLaxMode:
+		s.add("cookie.same_site", "lax")
+	case http.SameSiteStrictMode:
+		s.add("cookie.same_site", "strict")
+	case http.SameSiteNoneMode:
+		s.add("cookie.same_site", "none")
+	case http.SameSiteDefaultMode:
+	}
+	if cookie.Partitioned {
+		s.add("cookie.partitioned", "true")
+	}
+}
+
+func (s *serializer) stream(stream *model.Stream) {
+	s.add("stream", string(stream.Mode))
+	if stream.Boundary != defaultMultipartBoundary {
+		s.add("stream.boundary", stream.Boundary)
+	}
+	for _, chunk := range stream.Chunks {
+		s.add("chunk", singleLine(chunk.Data))
+		if chunk.Delay != nil {
+			s.add("chunk.delay", chunk.Delay.String())
+		}
+		if chunk.ContentType != "" {
+			s.add("chunk.content_type", chunk.ContentType)
+		}
+	}
+}
+
+func (s *serializer) eventStream(stream *model.EventStream) {
+	if stream.Interval != nil {
+		s.add("sse.interval", stream.Interval.String())
+	}
+	if stream.KeepAlive != nil {
+		s.add("sse.keep_alive", stream.KeepAlive.String())
+	}
+	if stream.Loop {
+		s.add("sse.loop", "true")
+	}
+	if stream.Hold {
+		s.add("sse.hold", "true")
+	}
+	for _, event := range stream.Events {
+		s.add("event", event.Type)
+		if event.ID != nil {
+			s.add("event.id", *event.ID)
+		}
+		for _, data := range event.Data {
+			s.add("event.data", data)
+		}
+		if event.Retry != nil {
+			s.add("event.retry", strconv.FormatUint(uint64(*event.Retry), 10))
+		}
+		if event.Delay != nil {
+			s.add("event.delay", event.Delay.String())
+		}
+	}
+}
+
+func (s *serializer) webSocket(script *model.WebSocketScript) {
+	for _, frame := range script.OnConnect {
+		s.add("ws.send", frame)
+	}
+	if script.Ping != nil {
+		s.add("ws.ping", script.Ping.String())
+	}
+	if script.Close != nil {
+		s.add("ws.close", closeFrame(script.Close))
+	}
+	if script.CloseAfter != nil {
+		s.add("ws.close_after", script.CloseAfter.String())
+	}
+	for _, reply := range script.Replies {
+		s.add("on", reply.Pattern.String())
+		for _, frame := range reply.Frames {
+			s.add("on.send", frame)
+		}
+		if reply.Close != nil {
+			s.add("on.close", closeFrame(reply.Close))
+		}
+	}
+}
+
+func latency(latency *model.Latency) string {
+	if latency.Distribution == model.LatencyPercentiles {
+		percentiles := make([]string, 0, len(latency.Percentiles))
+		for _, p := range latency.Percentiles {
+			percentiles = append(percentiles, "p"+strconv.FormatFloat(p.Percentile, 'f', -1, 64)+"="+p.Delay.String())
+		}
+		return strings.Join(percentiles, ",")
+	}
+	params := make([]string, 0, len(latency.Params))
+	for _, param := range latency.Params {
+		params = append(params, param.String())
+	}
+	return string(latency.Distribution) + "(" + strings.Join(params, ", ") + ")"
+}
+
+func faultValue(fault *model.Fault) string {
+	value := string(fault.Mode)
+	if fault.Mode == model.FaultTruncate || fault.Mode == model.FaultSlowBody {
+		value += "=" + strconv.Itoa(fault.Value)
+	}
+	if fault.Probability < 1 {
+		value += " " + strconv.FormatFloat(fault.Probability, 'f', -1, 64)
+	}
+	return value
+}
+
+func closeFrame(closeFrame *model.WebSocketClose) string {
+	return strings.TrimSpace(strconv.Itoa(closeFrame.Code) + " " + closeFrame.Reason)
+}
+
+// singleLine compacts multi-line JSON so it fits on an INI line, other values are returned as they are.
+func singleLine(value string) string {
+	if !strings.ContainsAny(value, "\r\n") {
+		return value
+	}
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, []byte(value)); err != nil {
+		return value
+	}
+	return compacted.String()
+}
+
+// sameSlice reports whether two slices share their elements, as inherited slices do.
+func sameSlice[T any](a, b []T) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
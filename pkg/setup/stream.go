@@ -0,0 +1,76 @@
+package setup
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+const defaultMultipartBoundary = "servmock"
+
+// parseStream handles `stream`, `stream.*` and `chunk`/`chunk.*` properties.
+// Like cookies, `chunk = <data>` starts a new chunk and following `chunk.*` properties belong to it.
+//
+//nolint:cyclop
+func parseStream(responseBehavior *model.ResponseBehavior, property ini.Property) error {
+	malformed := func(details string) error {
+		return &MalformedPropertyError{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   Ptr(details),
+		}
+	}
+
+	if responseBehavior.Stream == nil {
+		responseBehavior.Stream = &model.Stream{Mode: model.StreamChunked, Boundary: defaultMultipartBoundary}
+	}
+	stream := responseBehavior.Stream
+
+	switch property.Key {
+	case "stream":
+		mode, match := model.StreamModeFromString(property.Value)
+		if !match {
+			return malformed("Unknown stream mode: " + property.Value)
+		}
+		stream.Mode = mode
+		responseBehavior.SSE = mode == model.StreamSSE
+		for _, chunk := range stream.Chunks {
+			if mode == model.StreamNDJSON && !json.Valid([]byte(chunk.Data)) {
+				return malformed("Invalid NDJSON chunk, must be a single line of valid JSON: " + chunk.Data)
+			}
+		}
+	case "stream.boundary":
+		if property.Value == "" || strings.ContainsAny(property.Value, "\r\n") {
+			return malformed("Invalid stream.boundary, must be a non-empty single line")
+		}
+		stream.Boundary = property.Value
+	case "chunk":
+		if stream.Mode == model.StreamNDJSON && !json.Valid([]byte(property.Value)) {
+			return malformed("Invalid NDJSON chunk, must be a single line of valid JSON")
+		}
+		stream.Chunks = append(stream.Chunks, &model.Chunk{Data: property.Value})
+	case "chunk.delay", "chunk.content_type":
+		if len(stream.Chunks) == 0 {
+			return malformed("chunk must be set before other chunk properties")
+		}
+		chunk := stream.Chunks[len(stream.Chunks)-1]
+		if property.Key == "chunk.content_type" {
+			chunk.ContentType = property.Value
+			return nil
+		}
+		delay, err := time.ParseDuration(property.Value)
+		if err != nil || delay < 0 {
+			return malformed("Invalid chunk.delay, must be a non-negative duration")
+		}
+		chunk.Delay = &delay
+	default:
+		return malformed("Unknown stream property: " + property.Key)
+	}
+
+	return nil
+}
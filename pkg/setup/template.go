@@ -0,0 +1,165 @@
+package setup
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/StevenCyb/ServMock/pkg/ini"
+)
+
+const templatePrefix = "template "
+
+// extendsSeparator separates a section name from the templates it extends, e.g. `[GET /users : json_ok]`.
+var extendsSeparator = regexp.MustCompile(`\s+:\s*`)
+
+// template is an abstract section whose properties are merged into the sections extending it.
+type template struct {
+	section ini.Section
+	extends []string
+}
+
+// expandTemplates merges the properties of extended templates into the sections extending them and
+// removes the template sections. Template properties come first, so the own properties of a section
+// override them (or add to them for repeatable ones like cookies). If the default section sets
+// `inherit_headers = true`, its headers are merged into every behavior before any template.
+// Sections with errors are left out and their errors returned.
+func expandTemplates(sections []ini.Section) ([]ini.Section, []error) {
+	var errs []error
+	templates := map[string]template{}
+	var defaultHeaders []ini.Property
+	for _, section := range sections {
+		if name, found := strings.CutPrefix(section.Name, templatePrefix); found {
+			name, extends := splitExtends(name)
+			if name == "" || strings.ContainsAny(name, " \t") {
+				errs = append(errs, &MalformedBehaviorHeaderError{
+					Line:      section.Name,
+					LineIndex: section.LineIndex,
+					Column:    section.Column,
+					Source:    section.Source,
+					Details:   Ptr("Template name must be a single word"),
+				})
+				continue
+			}
+			templates[name] = template{section: section, extends: extends}
+		}
+	}
+
+	expanded := make([]ini.Section, 0, len(sections))
+	for _, section := range sections {
+		switch {
+		case strings.HasPrefix(section.Name, templatePrefix):
+			continue
+		case section.Name == "default":
+			var err error
+			if section, defaultHeaders, err = splitDefaultHeaders(section); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			name, extends := splitExtends(section.Name)
+			properties, err := templateProperties(templates, section, extends, nil)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			section.Name = name
+			section.Properties = slices.Concat(properties, section.Properties)
+		}
+		expanded = append(expanded, section)
+	}
+
+	if len(defaultHeaders) > 0 {
+		for i := range expanded {
+			if expanded[i].Name != "default" {
+				expanded[i].Properties = slices.Concat(defaultHeaders, expanded[i].Properties)
+			}
+		}
+	}
+	return expanded, errs
+}
+
+// splitExtends splits a section name into its own name and the names of the templates it extends.
+func splitExtends(name string) (string, []string) {
+	parts := extendsSeparator.Split(name, 2)
+	if len(parts) == 1 {
+		return name, nil
+	}
+	var extends []string
+	for _, extend := range strings.Split(parts[1], ",") {
+		extends = append(extends, strings.TrimSpace(extend))
+	}
+	return parts[0], extends
+}
+
+// templateProperties returns the properties of the extended templates in order,
+// each preceded by the properties of the templates it extends itself.
+func templateProperties(
+	templates map[string]template, section ini.Section, extends []string, chain []string,
+) ([]ini.Property, error) {
+	var properties []ini.Property
+	for _, name := range extends {
+		t, found := templates[name]
+		if !found {
+			return nil, &MalformedBehaviorHeaderError{
+				Line:      section.Name,
+				LineIndex: section.LineIndex,
+				Column:    section.Column,
+				Source:    section.Source,
+				Details:   Ptr("Unknown template: " + name),
+			}
+		}
+		if slices.Contains(chain, name) {
+			return nil, &MalformedBehaviorHeaderError{
+				Line:      t.section.Name,
+				LineIndex: t.section.LineIndex,
+				Column:    t.section.Column,
+				Source:    t.section.Source,
+				Details:   Ptr("Template cycle: " + strings.Join(append(chain, name), " -> ")),
+			}
+		}
+		inherited, err := templateProperties(templates, t.section, t.extends, append(slices.Clone(chain), name))
+		if err != nil {
+			return nil, err
+		}
+		properties = slices.Concat(properties, inherited, t.section.Properties)
+	}
+	return properties, nil
+}
+
+// splitDefaultHeaders removes the inherit_headers property from the default section
+// and returns the headers to merge into every behavior if it is enabled.
+func splitDefaultHeaders(section ini.Section) (ini.Section, []ini.Property, error) {
+	inherit := false
+	properties := make([]ini.Property, 0, len(section.Properties))
+	for _, property := range section.Properties {
+		if property.Key != "inherit_headers" {
+			properties = append(properties, property)
+			continue
+		}
+		value, err := strconv.ParseBool(property.Value)
+		if err != nil {
+			section.Properties = properties
+			return section, nil, &MalformedPropertyError{
+				LineIndex: property.LineIndex,
+				Column:    property.Column,
+				Source:    property.Source,
+				Line:      property.Key + "=" + property.Value,
+				Details:   Ptr("Invalid inherit_headers, must be true or false"),
+			}
+		}
+		inherit = value
+	}
+	section.Properties = properties
+
+	if !inherit {
+		return section, nil, nil
+	}
+	var headers []ini.Property
+	for _, property := range properties {
+		if property.Key == "header" {
+			headers = append(headers, property)
+		}
+	}
+	return section, headers, nil
+}
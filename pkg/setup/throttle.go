@@ -0,0 +1,87 @@
+package setup
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+// byteUnits maps the supported size units to their number of bytes, longest suffix first.
+//
+//nolint:gochecknoglobals
+var byteUnits = []struct {
+	suffix string
+	bytes  uint64
+}{
+	{"kib", 1 << 10}, {"mib", 1 << 20}, {"gib", 1 << 30},
+	{"kb", 1e3}, {"mb", 1e6}, {"gb", 1e9},
+	{"b", 1},
+}
+
+// parseByteSize parses a size like `512`, `64KiB` or `1MB` into bytes.
+func parseByteSize(value string) (uint64, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	multiplier := uint64(1)
+	for _, unit := range byteUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.bytes
+			break
+		}
+	}
+
+	size, err := strconv.ParseFloat(value, 64)
+	if err != nil || size <= 0 {
+		return 0, false
+	}
+	bytes := uint64(size * float64(multiplier))
+	return bytes, bytes > 0
+}
+
+func parseBandwidth(responseBehavior *model.ResponseBehavior, property ini.Property) error {
+	bandwidth, ok := parseByteSize(strings.TrimSuffix(strings.TrimSpace(property.Value), "/s"))
+	if !ok {
+		return &MalformedPropertyError{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   Ptr("Invalid bandwidth, expected a positive size per second like '64KiB/s'"),
+		}
+	}
+	responseBehavior.Bandwidth = &bandwidth
+	return nil
+}
+
+func parseChunkSize(responseBehavior *model.ResponseBehavior, property ini.Property) error {
+	chunkSize, ok := parseByteSize(property.Value)
+	if !ok {
+		return &MalformedPropertyError{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   Ptr("Invalid chunk size, expected a positive size like '1KiB'"),
+		}
+	}
+	responseBehavior.ChunkSize = &chunkSize
+	return nil
+}
+
+func parseChunkDelay(responseBehavior *model.ResponseBehavior, property ini.Property) error {
+	chunkDelay, err := time.ParseDuration(property.Value)
+	if err != nil || chunkDelay < 0 {
+		return &MalformedPropertyError{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   Ptr("Invalid chunk delay, must be a non-negative duration"),
+		}
+	}
+	responseBehavior.ChunkDelay = &chunkDelay
+	return nil
+}
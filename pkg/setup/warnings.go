@@ -0,0 +1,117 @@
+package setup
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+// builtBehavior is a behavior with the section it was built from.
+type builtBehavior struct {
+	behavior *model.Behavior
+	section  ini.Section
+}
+
+// redirect is a redirect property with the response it applies to.
+type redirect struct {
+	target   *model.ResponseBehavior
+	property ini.Property
+}
+
+// checkWarnings returns the problems of a behavior that do not block loading:
+// a repeat of 0, redirects without a 3xx status code, variants before the first step and behaviors that are never served
+// because an earlier behavior with the same method and a path covering theirs matches every request first.
+func checkWarnings(built []builtBehavior, section ini.Section, behavior *model.Behavior, redirects []redirect) []error {
+	var warnings []error
+	warnAt := func(property ini.Property, details string) {
+		warnings = append(warnings, &Warning{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   details,
+		})
+	}
+
+	for _, earlier := range built {
+		if earlier.behavior.Method == behavior.Method && coversPath(earlier.behavior.URL, behavior.URL) &&
+			matchesEveryRequest(earlier.behavior) && !exhausts(earlier.behavior) {
+			warnings = append(warnings, &Warning{
+				LineIndex: section.LineIndex,
+				Column:    section.Column,
+				Source:    section.Source,
+				Line:      "[" + section.Name + "]",
+				Details: "Behavior is never served, it is shadowed by [" + earlier.section.Name + "] at " +
+					location(earlier.section.Source, earlier.section.LineIndex, earlier.section.Column),
+			})
+			break
+		}
+	}
+
+	if behavior.Repeat != nil && *behavior.Repeat == 0 {
+		for i := len(section.Properties) - 1; i >= 0; i-- {
+			if section.Properties[i].Key == "repeat" {
+				warnAt(section.Properties[i], "Repeat of 0 never runs out, the behavior is served indefinitely")
+				break
+			}
+		}
+	}
+
+	if len(behavior.Steps) > 0 {
+		for _, property := range section.Properties {
+			if property.Key == "step" {
+				break
+			}
+			if property.Key == "variant" {
+				warnAt(property, "Variant is never served, steps only serve the variants declared within them")
+			}
+		}
+	}
+
+	for _, r := range redirects {
+		if r.target.StatusCode == nil || *r.target.StatusCode < 300 || *r.target.StatusCode > 399 {
+			status := "200"
+			if r.target.StatusCode != nil {
+				status = strconv.Itoa(int(*r.target.StatusCode))
+			}
+			warnAt(r.property, "Redirect is served with status code "+status+" instead of a 3xx status code")
+		}
+	}
+	return warnings
+}
+
+// matchesEveryRequest reports whether a behavior matches requests by method and path only.
+func matchesEveryRequest(behavior *model.Behavior) bool {
+	return behavior.Query == nil && behavior.RequestBody == nil && behavior.GraphQLMatch == nil
+}
+
+// exhausts reports whether a behavior stops being served after a number of requests.
+func exhausts(behavior *model.Behavior) bool {
+	return behavior.Repeat != nil && *behavior.Repeat > 0
+}
+
+// coversPath reports whether the URL pattern matches every path the other pattern matches,
+// e.g. `/users/{id}` covers `/users/me` and `/users/{name}`.
+func coversPath(pattern, other string) bool {
+	if pattern == other {
+		return true
+	}
+	patternSegments := strings.Split(pattern, "/")
+	otherSegments := strings.Split(other, "/")
+	if len(patternSegments) != len(otherSegments) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		// A path parameter matches any single non-empty segment, like in the server.
+		isParameter := len(segment) > 2 && strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+		if isParameter && otherSegments[i] != "" {
+			continue
+		}
+		if segment != otherSegments[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package setup
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StevenCyb/ServMock/pkg/ini"
+	"github.com/StevenCyb/ServMock/pkg/model"
+)
+
+const (
+	minCloseCode = 1000
+	maxCloseCode = 4999
+)
+
+// parseWebSocket handles `ws.*` script properties and `on`/`on.*` reply properties.
+// Like cookies, `on = <pattern>` starts a new reply and following `on.*` properties belong to it.
+//
+//nolint:cyclop
+func parseWebSocket(behavior *model.Behavior, responseBehavior *model.ResponseBehavior, property ini.Property) error {
+	malformed := func(details string) error {
+		return &MalformedPropertyError{
+			LineIndex: property.LineIndex,
+			Column:    property.Column,
+			Source:    property.Source,
+			Line:      property.Key + "=" + property.Value,
+			Details:   Ptr(details),
+		}
+	}
+
+	if behavior.Method != model.MethodWS {
+		return malformed("WebSocket properties require a WS behavior")
+	}
+	if responseBehavior.WebSocket == nil {
+		responseBehavior.WebSocket = &model.WebSocketScript{}
+	}
+	script := responseBehavior.WebSocket
+
+	var reply *model.WebSocketReply
+	switch {
+	case property.Key == "on":
+		pattern, err := regexp.Compile(property.Value)
+		if err != nil {
+			return malformed("Invalid on pattern: " + err.Error())
+		}
+		script.Replies = append(script.Replies, &model.WebSocketReply{Pattern: pattern})
+		return nil
+	case strings.HasPrefix(property.Key, "ws."):
+	case len(script.Replies) == 0:
+		return malformed("on must be set before other on properties")
+	default:
+		reply = script.Replies[len(script.Replies)-1]
+	}
+
+	switch property.Key {
+	case "ws.send":
+		script.OnConnect = append(script.OnConnect, property.Value)
+	case "ws.ping", "ws.close_after":
+		duration, err := time.ParseDuration(property.Value)
+		if err != nil || duration <= 0 {
+			return malformed("Invalid " + property.Key + ", must be a positive duration")
+		}
+		if property.Key == "ws.ping" {
+			script.Ping = &duration
+		} else {
+			script.CloseAfter = &duration
+		}
+	case "ws.close", "on.close":
+		closeFrame, ok := parseCloseFrame(property.Value)
+		if !ok {
+			return malformed("Invalid " + property.Key + ", expected '<code> [reason]' with a code between 1000 and 4999")
+		}
+		if reply != nil {
+			reply.Close = closeFrame
+		} else {
+			script.Close = closeFrame
+		}
+	case "on.send":
+		reply.Frames = append(reply.Frames, property.Value)
+	default:
+		return malformed("Unknown WebSocket property: " + property.Key)
+	}
+
+	return nil
+}
+
+func parseCloseFrame(value string) (*model.WebSocketClose, bool) {
+	parts := strings.SplitN(strings.TrimSpace(value), " ", twoParts)
+	code, err := strconv.Atoi(parts[0])
+	if err != nil || code < minCloseCode || code > maxCloseCode {
+		return nil, false
+	}
+	closeFrame := &model.WebSocketClose{Code: code}
+	if len(parts) == twoParts {
+		closeFrame.Reason = strings.TrimSpace(parts[1])
+	}
+	return closeFrame, true
+}
@@ -1,18 +1,36 @@
 package watcher
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"os"
+	"path/filepath"
 	"time"
+
+	"github.com/fsnotify/fsnotify"
 )
 
-// Watcher monitors a file for changes at a specified interval.
+// DefaultDebounce is the quiet period after the last file system event before the file is checked.
+const DefaultDebounce = 100 * time.Millisecond
+
+// Watcher monitors a file, or a group of files, for content changes.
+// It listens for file system events on the directories containing the files and falls back
+// to polling at the given interval when events are not available.
+// Events are debounced and the listener is only called when the content hash changes,
+// so atomic renames, symlink swaps (e.g. Kubernetes ConfigMaps) and files that are deleted
+// and recreated are handled without missed or duplicate reloads.
 type Watcher struct {
-	path        string
-	interval    int
-	listener    func(string)
-	running     bool
-	stopChan    chan struct{}
-	lastModTime int64
+	path     string
+	interval int
+	debounce time.Duration
+	polling  bool
+	files    func(string) ([]string, error)
+	listener func(string)
+	running  bool
+	stopChan chan struct{}
+	lastHash []byte
+	events   *fsnotify.Watcher
+	watched  map[string]bool
 }
 
 // NewWatcher creates a new Watcher for the given path and polling interval (ms).
@@ -20,6 +38,8 @@ func NewWatcher(path string, interval int) *Watcher {
 	return &Watcher{
 		path:     path,
 		interval: interval,
+		debounce: DefaultDebounce,
+		files:    func(path string) ([]string, error) { return []string{path}, nil },
 		stopChan: make(chan struct{}),
 	}
 }
@@ -29,15 +49,45 @@ func (w *Watcher) RegisterListener(listener func(string)) {
 	w.listener = listener
 }
 
-// Start begins polling for file changes.
+// SetDebounce sets how long to wait for further events before checking the file.
+func (w *Watcher) SetDebounce(debounce time.Duration) {
+	w.debounce = debounce
+}
+
+// SetPolling forces the polling backend, e.g. for network file systems without event support.
+func (w *Watcher) SetPolling(polling bool) {
+	w.polling = polling
+}
+
+// SetFiles sets how the watched path resolves to files, e.g. for directories or glob patterns.
+// The files are resolved again on every check, so files added to or removed from the group are detected.
+// The listener is still called with the watched path.
+func (w *Watcher) SetFiles(files func(path string) ([]string, error)) {
+	w.files = files
+}
+
+// Start calls the listener once and begins watching for file changes.
 func (w *Watcher) Start() {
 	if w.running {
 		return
 	}
 	w.running = true
+	w.lastHash, _ = w.hash()
 	if w.listener != nil {
 		w.listener(w.path)
 	}
+
+	if !w.polling {
+		if events, err := fsnotify.NewWatcher(); err == nil {
+			w.events = events
+			w.watched = map[string]bool{}
+			if w.watchDirectories() {
+				go w.watch()
+				return
+			}
+			events.Close()
+		}
+	}
 	go w.poll()
 }
 
@@ -50,6 +100,61 @@ func (w *Watcher) Stop() {
 	close(w.stopChan)
 }
 
+// watchDirectories adds the directories of the watched path and its files to the event watcher.
+// Watching directories instead of files keeps working when a file is replaced or added.
+// It returns false if none of them could be watched.
+func (w *Watcher) watchDirectories() bool {
+	directories := []string{filepath.Dir(w.path)}
+	if fi, err := os.Stat(w.path); err == nil && fi.IsDir() {
+		directories = append(directories, w.path)
+	}
+	if files, err := w.files(w.path); err == nil {
+		for _, file := range files {
+			directories = append(directories, filepath.Dir(file))
+		}
+	}
+	for _, directory := range directories {
+		if w.watched[directory] {
+			continue
+		}
+		if err := w.events.Add(directory); err == nil {
+			w.watched[directory] = true
+		}
+	}
+	return len(w.watched) > 0
+}
+
+func (w *Watcher) watch() {
+	events := w.events
+	var debounce <-chan time.Time
+	for {
+		select {
+		case _, ok := <-events.Events:
+			if !ok {
+				go w.poll()
+				return
+			}
+			// Every event in the directory is a candidate since symlink swaps only touch sibling entries.
+			debounce = time.After(w.debounce)
+		case <-debounce:
+			debounce = nil
+			w.checkFile()
+			w.watchDirectories()
+		case _, ok := <-events.Errors:
+			if ok {
+				// Events may have been dropped (e.g. queue overflow), check the file anyway.
+				debounce = time.After(w.debounce)
+				continue
+			}
+			go w.poll()
+			return
+		case <-w.stopChan:
+			events.Close()
+			return
+		}
+	}
+}
+
 func (w *Watcher) poll() {
 	ticker := time.NewTicker(time.Duration(w.interval) * time.Millisecond)
 	defer ticker.Stop()
@@ -64,19 +169,36 @@ func (w *Watcher) poll() {
 }
 
 func (w *Watcher) checkFile() {
-	fi, err := os.Stat(w.path)
+	hash, err := w.hash()
 	if err != nil {
+		// A file is missing for now, wait until it is recreated.
 		return
 	}
-	modTime := fi.ModTime().UnixNano()
-	if w.lastModTime == 0 {
-		w.lastModTime = modTime
+	if bytes.Equal(hash, w.lastHash) {
 		return
 	}
-	if modTime != w.lastModTime {
-		if w.listener != nil {
-			w.listener(w.path)
+	w.lastHash = hash
+	if w.listener != nil {
+		w.listener(w.path)
+	}
+}
+
+// hash returns the SHA-256 hash over the names and contents of the watched files.
+func (w *Watcher) hash() ([]byte, error) {
+	files, err := w.files(w.path)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
 		}
-		w.lastModTime = modTime
+		h.Write([]byte(file))
+		h.Write([]byte{0})
+		h.Write(content)
+		h.Write([]byte{0})
 	}
+	return h.Sum(nil), nil
 }
@@ -2,6 +2,9 @@ package watcher
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -91,3 +94,167 @@ func TestWatcher_NoListenerNoPanic(t *testing.T) {
 
 	time.Sleep(50 * time.Millisecond)
 }
+
+type listenerCounter struct {
+	mutex sync.Mutex
+	calls int
+}
+
+func (c *listenerCounter) listen(_ string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls++
+}
+
+func (c *listenerCounter) count() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.calls
+}
+
+func startCounting(t *testing.T, w *Watcher) *listenerCounter {
+	t.Helper()
+	counter := &listenerCounter{}
+	w.RegisterListener(counter.listen)
+	w.Start()
+	t.Cleanup(w.Stop)
+	require.Equal(t, 1, counter.count())
+	return counter
+}
+
+func TestWatcher_AtomicRenameTriggersListener(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.ini")
+	require.NoError(t, os.WriteFile(path, []byte("a"), 0o644))
+
+	counter := startCounting(t, NewWatcher(path, 5000))
+
+	tmp := filepath.Join(dir, ".config.ini.swp")
+	require.NoError(t, os.WriteFile(tmp, []byte("b"), 0o644))
+	require.NoError(t, os.Rename(tmp, path))
+
+	assert.Eventually(t, func() bool { return counter.count() == 2 }, time.Second, 20*time.Millisecond)
+}
+
+func TestWatcher_DeletedAndRecreatedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.ini")
+	require.NoError(t, os.WriteFile(path, []byte("a"), 0o644))
+
+	counter := startCounting(t, NewWatcher(path, 5000))
+
+	require.NoError(t, os.Remove(path))
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, 1, counter.count(), "Listener should not be triggered while the file is missing")
+
+	require.NoError(t, os.WriteFile(path, []byte("b"), 0o644))
+	assert.Eventually(t, func() bool { return counter.count() == 2 }, time.Second, 20*time.Millisecond)
+}
+
+func TestWatcher_SymlinkSwap(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "v1"), 0o755))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "v2"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "v1", "config.ini"), []byte("a"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "v2", "config.ini"), []byte("b"), 0o644))
+	require.NoError(t, os.Symlink("v1", filepath.Join(dir, "..data")))
+	path := filepath.Join(dir, "config.ini")
+	require.NoError(t, os.Symlink(filepath.Join("..data", "config.ini"), path))
+
+	counter := startCounting(t, NewWatcher(path, 5000))
+
+	// Same procedure as the kubelet uses to update ConfigMap volumes.
+	require.NoError(t, os.Symlink("v2", filepath.Join(dir, "..data_tmp")))
+	require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+
+	assert.Eventually(t, func() bool { return counter.count() == 2 }, time.Second, 20*time.Millisecond)
+}
+
+func TestWatcher_DebounceCoalescesWrites(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.ini")
+	require.NoError(t, os.WriteFile(path, []byte("a"), 0o644))
+
+	w := NewWatcher(path, 5000)
+	w.SetDebounce(200 * time.Millisecond)
+	counter := startCounting(t, w)
+
+	for i := range 5 {
+		require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("b", i+1)), 0o644))
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	assert.Eventually(t, func() bool { return counter.count() == 2 }, time.Second, 20*time.Millisecond)
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, 2, counter.count())
+}
+
+func TestWatcher_UnchangedContentDoesNotTrigger(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.ini")
+	require.NoError(t, os.WriteFile(path, []byte("a"), 0o644))
+
+	counter := startCounting(t, NewWatcher(path, 5000))
+
+	require.NoError(t, os.WriteFile(path, []byte("a"), 0o644))
+	now := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, now, now))
+
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, 1, counter.count())
+}
+
+func TestWatcher_PollingFallback(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.ini")
+	require.NoError(t, os.WriteFile(path, []byte("a"), 0o644))
+
+	w := NewWatcher(path, 50)
+	w.SetPolling(true)
+	counter := startCounting(t, w)
+
+	require.NoError(t, os.Remove(path))
+	time.Sleep(150 * time.Millisecond)
+	require.NoError(t, os.WriteFile(path, []byte("b"), 0o644))
+
+	assert.Eventually(t, func() bool { return counter.count() == 2 }, time.Second, 20*time.Millisecond)
+}
+
+func TestWatcher_FileGroup(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.ini"), []byte("a"), 0o644))
+	pattern := filepath.Join(dir, "*.ini")
+
+	w := NewWatcher(pattern, 5000)
+	w.SetFiles(filepath.Glob)
+	var paths []string
+	var mutex sync.Mutex
+	w.RegisterListener(func(path string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		paths = append(paths, path)
+	})
+	w.Start()
+	defer w.Stop()
+	calls := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(paths)
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.ini"), []byte("b"), 0o644))
+	assert.Eventually(t, func() bool { return calls() == 2 }, time.Second, 20*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.ini"), []byte("c"), 0o644))
+	assert.Eventually(t, func() bool { return calls() == 3 }, time.Second, 20*time.Millisecond)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "a.ini")))
+	assert.Eventually(t, func() bool { return calls() == 4 }, time.Second, 20*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o644))
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, 4, calls())
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, pattern, paths[3])
+}